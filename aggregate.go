@@ -0,0 +1,187 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Aggregate represents a monoid aggregating the items of a B-tree, such as
+// counts, sums, maxima or bounding intervals.
+type Aggregate struct {
+	// Zero is the aggregate of no items, the identity of Combine.
+	Zero interface{}
+	// FromItem returns the aggregate of a single item.
+	FromItem func(item Item) interface{}
+	// Combine returns the aggregate of the items aggregated by a followed by
+	// the items aggregated by b. It must be associative.
+	Combine func(a, b interface{}) interface{}
+}
+
+// EnableAggregate enables the range aggregates of the items by the monoid,
+// replacing the aggregates enabled before. The aggregates of the nodes are
+// computed lazily and a mutation only invalidates the aggregates of the nodes
+// on its path, so AggregateRange takes O(log n) time. The aggregates are cached
+// under the monoid, so the clones sharing the nodes may enable other monoids.
+func (t *Tree) EnableAggregate(a Aggregate) {
+	t.agg = &a
+	t.numeric = false
+}
+
+// AggregateRange returns the aggregate of the items in the range [lo, hi) of
+// the B-tree in ascending order. A nil bound leaves the range unbounded on
+// that side.
+func (t *Tree) AggregateRange(lo, hi Item) interface{} {
+	if t.agg == nil {
+		panic("aggregates not enabled")
+	}
+	if lo != nil && hi != nil && !lo.Less(hi) {
+		return t.agg.Zero
+	}
+	return t.root.aggregateRange(lo, hi, t.agg, t.agg.Zero)
+}
+
+// aggregate is the count, sum, min and max of the numeric values of some items.
+type aggregate struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func combineAggregates(a, b interface{}) interface{} {
+	x, y := a.(aggregate), b.(aggregate)
+	if x.count == 0 {
+		return y
+	} else if y.count == 0 {
+		return x
+	}
+	if y.min < x.min {
+		x.min = y.min
+	}
+	if y.max > x.max {
+		x.max = y.max
+	}
+	x.count += y.count
+	x.sum += y.sum
+	return x
+}
+
+// EnableAggregates enables the range aggregates of the numeric values of the
+// items, replacing the aggregates enabled before, so SumRange, MinRange,
+// MaxRange and AvgRange take O(log n) time.
+func (t *Tree) EnableAggregates(value func(item Item) float64) {
+	t.EnableAggregate(Aggregate{
+		Zero: aggregate{},
+		FromItem: func(item Item) interface{} {
+			v := value(item)
+			return aggregate{count: 1, sum: v, min: v, max: v}
+		},
+		Combine: combineAggregates,
+	})
+	t.numeric = true
+}
+
+// SumRange returns the sum of the values of the items in the range [lo, hi)
+// of the B-tree. A nil bound leaves the range unbounded on that side.
+func (t *Tree) SumRange(lo, hi Item) float64 {
+	return t.numericRange(lo, hi).sum
+}
+
+// MinRange returns the min value of the items in the range [lo, hi) of the
+// B-tree, and false if the range is empty.
+func (t *Tree) MinRange(lo, hi Item) (float64, bool) {
+	a := t.numericRange(lo, hi)
+	return a.min, a.count > 0
+}
+
+// MaxRange returns the max value of the items in the range [lo, hi) of the
+// B-tree, and false if the range is empty.
+func (t *Tree) MaxRange(lo, hi Item) (float64, bool) {
+	a := t.numericRange(lo, hi)
+	return a.max, a.count > 0
+}
+
+// AvgRange returns the average value of the items in the range [lo, hi) of
+// the B-tree, and false if the range is empty.
+func (t *Tree) AvgRange(lo, hi Item) (float64, bool) {
+	a := t.numericRange(lo, hi)
+	if a.count == 0 {
+		return 0, false
+	}
+	return a.sum / float64(a.count), true
+}
+
+func (t *Tree) numericRange(lo, hi Item) aggregate {
+	if !t.numeric {
+		panic("numeric aggregates not enabled")
+	}
+	return t.AggregateRange(lo, hi).(aggregate)
+}
+
+// aggregateCache is the aggregate of a subtree cached with the monoid that
+// computed it.
+type aggregateCache struct {
+	monoid *Aggregate
+	value  interface{}
+}
+
+func (n *Node) aggregate(a *Aggregate) interface{} {
+	if c := n.cachedAggregate(); c != nil && c.monoid == a {
+		return c.value
+	}
+	acc := a.Zero
+	for i, item := range n.items {
+		if len(n.children) > 0 {
+			acc = a.Combine(acc, n.children[i].aggregate(a))
+		}
+		acc = a.Combine(acc, a.FromItem(item))
+	}
+	if len(n.children) > 0 {
+		acc = a.Combine(acc, n.children[len(n.items)].aggregate(a))
+	}
+	n.agg.Store(&aggregateCache{monoid: a, value: acc})
+	return acc
+}
+
+// cachedAggregate returns the aggregate cached in the node, which is loaded
+// and stored atomically like the content hash.
+func (n *Node) cachedAggregate() *aggregateCache {
+	c, _ := n.agg.Load().(*aggregateCache)
+	return c
+}
+
+// aggregateRange combines the acc with the items in the range [lo, hi) of the
+// subtree in ascending order, using the cached aggregates of the children
+// entirely in the range.
+func (n *Node) aggregateRange(lo, hi Item, a *Aggregate, acc interface{}) interface{} {
+	if n == nil {
+		return acc
+	}
+	if lo == nil && hi == nil {
+		return a.Combine(acc, n.aggregate(a))
+	}
+	start, found := 0, false
+	if lo != nil {
+		start, found = n.items.search(lo)
+	}
+	end := len(n.items)
+	if hi != nil {
+		end, _ = n.items.search(hi)
+	}
+	if len(n.children) > 0 {
+		if start == end {
+			return n.children[start].aggregateRange(lo, hi, a, acc)
+		}
+		if !found {
+			acc = n.children[start].aggregateRange(lo, nil, a, acc)
+		}
+	}
+	for i := start; i < end; i++ {
+		acc = a.Combine(acc, a.FromItem(n.items[i]))
+		if len(n.children) > 0 && i+1 < end {
+			acc = a.Combine(acc, n.children[i+1].aggregate(a))
+		}
+	}
+	if len(n.children) > 0 {
+		acc = n.children[end].aggregateRange(nil, hi, a, acc)
+	}
+	return acc
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestAggregates(t *testing.T) {
+	tree := New(2)
+	value := func(item Item) float64 {
+		return float64(item.(*pointer).value % 7)
+	}
+	tree.EnableAggregates(value)
+	if _, ok := tree.MinRange(nil, nil); ok || tree.SumRange(nil, nil) != 0 {
+		t.Error("")
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(&pointer{i})
+	}
+	check := func(tree *Tree) {
+		for lo := -1; lo < 102; lo += 3 {
+			for hi := lo - 2; hi < 102; hi += 5 {
+				var sum, min, max float64
+				count := 0
+				tree.AscendRange(&pointer{lo}, &pointer{hi}, func(item Item) bool {
+					v := value(item)
+					if count == 0 || v < min {
+						min = v
+					}
+					if count == 0 || v > max {
+						max = v
+					}
+					sum += v
+					count++
+					return true
+				})
+				if s := tree.SumRange(&pointer{lo}, &pointer{hi}); s != sum {
+					t.Error(lo, hi, s, sum)
+				}
+				if m, ok := tree.MinRange(&pointer{lo}, &pointer{hi}); ok != (count > 0) || ok && m != min {
+					t.Error(lo, hi, m, min)
+				}
+				if m, ok := tree.MaxRange(&pointer{lo}, &pointer{hi}); ok != (count > 0) || ok && m != max {
+					t.Error(lo, hi, m, max)
+				}
+				if avg, ok := tree.AvgRange(&pointer{lo}, &pointer{hi}); ok != (count > 0) || ok && avg != sum/float64(count) {
+					t.Error(lo, hi, avg)
+				}
+			}
+		}
+	}
+	check(tree)
+	if tree.SumRange(nil, nil) != 295 || tree.SumRange(nil, &pointer{7}) != 21 || tree.SumRange(&pointer{98}, nil) != 1 {
+		t.Error(tree.SumRange(nil, nil))
+	}
+	clone := tree.Clone()
+	for i := 0; i < 100; i += 3 {
+		tree.Delete(&pointer{i})
+	}
+	tree.Insert(&pointer{200})
+	check(tree)
+	check(clone)
+	value = func(item Item) float64 {
+		return float64(item.(*pointer).value)
+	}
+	tree.EnableAggregates(value)
+	check(tree)
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	New(2).SumRange(nil, nil)
+}
+
+func TestAggregateRange(t *testing.T) {
+	tree := New(2)
+	concat := Aggregate{
+		Zero: "",
+		FromItem: func(item Item) interface{} {
+			return string(rune('a' + int(item.(Int))%26))
+		},
+		Combine: func(a, b interface{}) interface{} {
+			return a.(string) + b.(string)
+		},
+	}
+	tree.EnableAggregate(concat)
+	if tree.AggregateRange(nil, nil) != "" {
+		t.Error("")
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	check := func() {
+		for lo := -1; lo < 102; lo += 3 {
+			for hi := lo - 2; hi < 102; hi += 5 {
+				want := ""
+				tree.AscendRange(Int(lo), Int(hi), func(item Item) bool {
+					want += concat.FromItem(item).(string)
+					return true
+				})
+				if got := tree.AggregateRange(Int(lo), Int(hi)); got != want {
+					t.Error(lo, hi, got, want)
+				}
+			}
+		}
+	}
+	check()
+	if tree.AggregateRange(nil, Int(3)) != "abc" || tree.AggregateRange(Int(98), nil) != "uv" {
+		t.Error(tree.AggregateRange(nil, Int(3)))
+	}
+	for i := 0; i < 100; i += 4 {
+		tree.Delete(Int(i))
+	}
+	check()
+	tree.EnableAggregates(func(item Item) float64 { return float64(item.(Int)) })
+	if tree.SumRange(Int(1), Int(4)) != 6 {
+		t.Error(tree.SumRange(Int(1), Int(4)))
+	}
+	tree.EnableAggregate(concat)
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	tree.SumRange(nil, nil)
+}
+
+func TestAggregateClone(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	tree.EnableAggregates(func(item Item) float64 { return float64(item.(Int)) })
+	if tree.SumRange(nil, nil) != 4950 {
+		t.Error(tree.SumRange(nil, nil))
+	}
+	clone := tree.Clone()
+	clone.EnableAggregate(Aggregate{
+		Zero:     0,
+		FromItem: func(item Item) interface{} { return 1 },
+		Combine:  func(a, b interface{}) interface{} { return a.(int) + b.(int) },
+	})
+	if clone.AggregateRange(nil, nil) != 100 {
+		t.Error(clone.AggregateRange(nil, nil))
+	}
+	if tree.SumRange(nil, nil) != 4950 || tree.SumRange(Int(10), Int(20)) != 145 {
+		t.Error(tree.SumRange(nil, nil))
+	}
+	if clone.AggregateRange(Int(10), Int(20)) != 10 {
+		t.Error(clone.AggregateRange(Int(10), Int(20)))
+	}
+}
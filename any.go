@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrType is returned when an item or a value is of an unsupported type.
+var ErrType = errors.New("bad value type")
+
+// AnyTree represents a B-tree of values of any type ordered by a comparator,
+// for values whose concrete types are only known at runtime. The accepted
+// types are checked when the values are inserted.
+type AnyTree struct {
+	tree  *Tree
+	less  func(a, b interface{}) bool
+	types map[reflect.Type]bool
+}
+
+type anyItem struct {
+	v interface{}
+	t *AnyTree
+}
+
+func (a anyItem) Less(b Item) bool {
+	return a.t.less(a.v, b.(anyItem).v)
+}
+
+// NewAny returns a new AnyTree with the given degree ordering the values by
+// the less func. If types are given, only the values of these types are accepted.
+func NewAny(degree int, less func(a, b interface{}) bool, types ...reflect.Type) *AnyTree {
+	if less == nil {
+		panic("nil less func")
+	}
+	t := &AnyTree{tree: New(degree), less: less}
+	if len(types) > 0 {
+		t.types = make(map[reflect.Type]bool, len(types))
+		for _, typ := range types {
+			t.types[typ] = true
+		}
+	}
+	return t
+}
+
+// check returns an error wrapping ErrType if the value is not accepted.
+func (t *AnyTree) check(v interface{}) error {
+	if v == nil {
+		return fmt.Errorf("%w: nil", ErrType)
+	}
+	if t.types != nil && !t.types[reflect.TypeOf(v)] {
+		return fmt.Errorf("%w: %T", ErrType, v)
+	}
+	return nil
+}
+
+// Length returns the number of values currently in the B-tree.
+func (t *AnyTree) Length() int {
+	return t.tree.Length()
+}
+
+// Insert inserts the value into the B-tree, replacing the equal value.
+func (t *AnyTree) Insert(v interface{}) error {
+	if err := t.check(v); err != nil {
+		return err
+	}
+	t.tree.Insert(anyItem{v, t})
+	return nil
+}
+
+// Get returns the value of the B-tree equal to the v and whether it was found.
+func (t *AnyTree) Get(v interface{}) (interface{}, bool) {
+	if t.check(v) != nil {
+		return nil, false
+	}
+	if item, ok := t.tree.Get(anyItem{v, t}); ok {
+		return item.(anyItem).v, true
+	}
+	return nil, false
+}
+
+// Delete deletes the value of the B-tree equal to the v and reports whether it existed.
+func (t *AnyTree) Delete(v interface{}) bool {
+	if t.check(v) != nil {
+		return false
+	}
+	_, ok := t.tree.DeleteItem(anyItem{v, t})
+	return ok
+}
+
+// Ascend calls the fn for every value of the B-tree in ascending order until
+// the fn returns false.
+func (t *AnyTree) Ascend(fn func(v interface{}) bool) {
+	t.tree.Ascend(func(item Item) bool {
+		return fn(item.(anyItem).v)
+	})
+}
+
+// AscendRange calls the fn for every value in the range [greaterOrEqual, lessThan)
+// of the B-tree in ascending order until the fn returns false. A nil bound
+// leaves the range unbounded on that side.
+func (t *AnyTree) AscendRange(greaterOrEqual, lessThan interface{}, fn func(v interface{}) bool) {
+	var lo, hi Item
+	if greaterOrEqual != nil {
+		lo = anyItem{greaterOrEqual, t}
+	}
+	if lessThan != nil {
+		hi = anyItem{lessThan, t}
+	}
+	t.tree.AscendRange(lo, hi, func(item Item) bool {
+		return fn(item.(anyItem).v)
+	})
+}
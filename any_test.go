@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnyTree(t *testing.T) {
+	less := func(a, b interface{}) bool {
+		x, xok := a.(int)
+		y, yok := b.(int)
+		if xok && yok {
+			return x < y
+		} else if xok != yok {
+			return xok
+		}
+		return a.(string) < b.(string)
+	}
+	tree := NewAny(2, less, reflect.TypeOf(0), reflect.TypeOf(""))
+	for i := 0; i < 10; i++ {
+		if err := tree.Insert(9 - i); err != nil {
+			t.Error(err)
+		}
+	}
+	for _, s := range []string{"b", "a", "c"} {
+		if err := tree.Insert(s); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := tree.Insert(1.5); !errors.Is(err, ErrType) {
+		t.Error(err)
+	}
+	if err := tree.Insert(nil); !errors.Is(err, ErrType) {
+		t.Error(err)
+	}
+	if tree.Length() != 13 {
+		t.Error(tree.Length())
+	}
+	var got []interface{}
+	tree.Ascend(func(v interface{}) bool {
+		got = append(got, v)
+		return true
+	})
+	if len(got) != 13 || got[0] != 0 || got[9] != 9 || got[10] != "a" || got[12] != "c" {
+		t.Error(got)
+	}
+	got = got[:0]
+	tree.AscendRange(8, "b", func(v interface{}) bool {
+		got = append(got, v)
+		return true
+	})
+	if len(got) != 3 || got[0] != 8 || got[2] != "a" {
+		t.Error(got)
+	}
+	count := 0
+	tree.AscendRange(nil, nil, func(v interface{}) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Error(count)
+	}
+	if v, ok := tree.Get("b"); !ok || v != "b" {
+		t.Error(v)
+	}
+	if _, ok := tree.Get(1.5); ok {
+		t.Error("")
+	}
+	if _, ok := tree.Get(10); ok {
+		t.Error("")
+	}
+	if !tree.Delete(3) || tree.Delete(3) || tree.Delete(1.5) || tree.Length() != 12 {
+		t.Error(tree.Length())
+	}
+	open := NewAny(2, func(a, b interface{}) bool { return a.(float64) < b.(float64) })
+	if err := open.Insert(1.5); err != nil || open.Length() != 1 {
+		t.Error(err)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	NewAny(2, nil)
+}
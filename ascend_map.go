@@ -0,0 +1,16 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package btree
+
+// AscendMap calls the fn for the projection of every item in the range [lo, hi)
+// of the B-tree in ascending order until the fn returns false. A nil bound
+// leaves the range unbounded on that side.
+func AscendMap[R any](t *Tree, lo, hi Item, project func(item Item) R, fn func(r R) bool) {
+	t.root.ascendRange(lo, hi, func(item Item) bool {
+		return fn(project(item))
+	})
+}
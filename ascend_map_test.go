@@ -0,0 +1,42 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package btree
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestAscendMap(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	var got []string
+	AscendMap(tree, Int(10), Int(20), func(item Item) string {
+		return strconv.Itoa(int(item.(Int)))
+	}, func(s string) bool {
+		got = append(got, s)
+		return true
+	})
+	if len(got) != 10 || got[0] != "10" || got[9] != "19" {
+		t.Error(got)
+	}
+	count := 0
+	AscendMap(tree, nil, nil, func(item Item) int {
+		return int(item.(Int)) * 2
+	}, func(v int) bool {
+		if v != count*2 {
+			t.Error(v, count*2)
+		}
+		count++
+		return count < 50
+	})
+	if count != 50 {
+		t.Error(count)
+	}
+}
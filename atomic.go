@@ -0,0 +1,71 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AtomicTree represents a B-tree whose readers never take locks. Writers are
+// serialized, apply their mutations to a clone of the B-tree and publish it by
+// swapping the root tree atomically, so readers always see a consistent tree.
+//
+// A mutation of the clone copies only the O(log n) nodes on its path from the
+// root, and the published trees share all the other nodes. The content hashes
+// and the aggregates that readers cache in the shared nodes are loaded and
+// stored atomically, so the readers may call MerkleRoot and AggregateRange, and
+// iterate from the min and max nodes of the loaded tree.
+type AtomicTree struct {
+	mu    sync.Mutex
+	value atomic.Value
+}
+
+// NewAtomic returns a new AtomicTree with the given degree.
+func NewAtomic(degree int) *AtomicTree {
+	t := &AtomicTree{}
+	t.value.Store(New(degree))
+	return t
+}
+
+// Load returns the current B-tree without locking. The returned tree must not
+// be modified, but may be cloned, and is not affected by later updates.
+func (t *AtomicTree) Load() *Tree {
+	return t.value.Load().(*Tree)
+}
+
+// Update calls the fn with a clone of the current B-tree and publishes the clone
+// after the fn returns. Several writes batched into one Update copy the shared
+// nodes once.
+func (t *AtomicTree) Update(fn func(tree *Tree)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tree := t.Load().Clone()
+	fn(tree)
+	t.value.Store(tree)
+}
+
+// Insert inserts the item into the B-tree.
+func (t *AtomicTree) Insert(item Item) {
+	t.Update(func(tree *Tree) {
+		tree.Insert(item)
+	})
+}
+
+// Delete deletes the node of the B-tree with the item.
+func (t *AtomicTree) Delete(item Item) {
+	t.Update(func(tree *Tree) {
+		tree.Delete(item)
+	})
+}
+
+// Search searches the Item of the B-tree without locking.
+func (t *AtomicTree) Search(item Item) Item {
+	return t.Load().Search(item)
+}
+
+// Length returns the number of items currently in the B-tree without locking.
+func (t *AtomicTree) Length() int {
+	return t.Load().Length()
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAtomicTree(t *testing.T) {
+	tree := NewAtomic(3)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				view := tree.Load()
+				count := 0
+				view.Ascend(func(item Item) bool {
+					count++
+					return true
+				})
+				if count != view.Length() {
+					t.Error(count, view.Length())
+				}
+				clone := view.Clone()
+				clone.Insert(Int(-1))
+				if clone.Length() != view.Length()+1 || view.Search(Int(-1)) != nil {
+					t.Error(clone.Length(), view.Length())
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		tree.Update(func(tree *Tree) {
+			for j := 0; j < 10; j++ {
+				tree.Insert(Int(i*10 + j))
+			}
+		})
+	}
+	before := tree.Load()
+	tree.Insert(Int(1000))
+	tree.Delete(Int(0))
+	close(done)
+	wg.Wait()
+	if tree.Length() != 1000 || before.Length() != 1000 {
+		t.Error(tree.Length(), before.Length())
+	}
+	if tree.Search(Int(0)) != nil || tree.Search(Int(1000)) == nil || before.Search(Int(0)) == nil {
+		t.Error("")
+	}
+	testShared(tree.Load(), t)
+	testShared(before, t)
+	shared := make(map[*Node]bool)
+	var walk func(n *Node, fn func(n *Node))
+	walk = func(n *Node, fn func(n *Node)) {
+		fn(n)
+		for _, child := range n.children {
+			walk(child, fn)
+		}
+	}
+	before = tree.Load()
+	walk(before.Root(), func(n *Node) { shared[n] = true })
+	tree.Insert(Int(2000))
+	copied := 0
+	walk(tree.Load().Root(), func(n *Node) {
+		if !shared[n] {
+			copied++
+		}
+	})
+	if copied > tree.Load().Root().Depth()*3 {
+		t.Error(copied)
+	}
+}
+
+func TestAtomicTreeCaches(t *testing.T) {
+	tree := NewAtomic(3)
+	tree.Update(func(tree *Tree) {
+		tree.EnableMerkle(func(item Item) []byte { return []byte{byte(item.(Int))} })
+		tree.EnableAggregates(func(item Item) float64 { return float64(item.(Int)) })
+		tree.Insert(Int(0))
+	})
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				view := tree.Load()
+				var sum float64
+				view.Ascend(func(item Item) bool {
+					sum += float64(item.(Int))
+					return true
+				})
+				if s := view.SumRange(nil, nil); s != sum {
+					t.Error(s, sum)
+				}
+				view.SumRange(Int(10), Int(100))
+				if view.MerkleRoot() == nil {
+					t.Error("")
+				}
+				testIteratorAscend(view, t)
+				testIteratorDescend(view, t)
+			}
+		}()
+	}
+	for i := 1; i < 1000; i++ {
+		tree.Insert(Int(i))
+	}
+	close(done)
+	wg.Wait()
+	if tree.Load().SumRange(nil, nil) != 499500 {
+		t.Error(tree.Load().SumRange(nil, nil))
+	}
+}
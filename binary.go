@@ -0,0 +1,249 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// binaryVersion is the version of the binary format.
+const binaryVersion = 1
+
+var (
+	// ErrCodec is returned when a B-tree without a codec is marshaled or unmarshaled.
+	ErrCodec = errors.New("no codec")
+	// ErrBinary is returned when the binary data of a B-tree is malformed.
+	ErrBinary = errors.New("bad binary data")
+)
+
+// SetCodec sets the codec of the items used by MarshalBinary, UnmarshalBinary,
+// WriteTo and ReadFrom. The codec is inherited by clones.
+func (t *Tree) SetCodec(c Codec) {
+	t.codec = c
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The data
+// holds the version of the format, the degree and the nodes of the B-tree in
+// preorder, so the structure is preserved by UnmarshalBinary.
+func (t *Tree) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := t.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the items and the degree of the B-tree with the ones in the data
+// written by MarshalBinary, decoding the items by the codec set by SetCodec.
+func (t *Tree) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	loaded, _, err := t.readFrom(r)
+	if err == io.ErrUnexpectedEOF || err == nil && r.Len() > 0 {
+		return ErrBinary
+	} else if err != nil {
+		return err
+	}
+	t.load(loaded)
+	return nil
+}
+
+// WriteTo implements the io.WriterTo interface. It streams the B-tree to the w
+// in the format of MarshalBinary, encoding one item at a time by the codec set
+// by SetCodec, and returns the number of bytes written.
+func (t *Tree) WriteTo(w io.Writer) (n int64, err error) {
+	if t.codec == nil {
+		return 0, ErrCodec
+	}
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	bw.WriteByte(binaryVersion)
+	putUvarint(bw, uint64(t.degree))
+	putUvarint(bw, uint64(t.length))
+	height := 0
+	for n := t.root; n != nil && len(n.children) > 0; n = n.children[0] {
+		height++
+	}
+	putUvarint(bw, uint64(height))
+	if t.root != nil {
+		err = t.marshalNode(bw, t.root)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	return cw.n, err
+}
+
+func (t *Tree) marshalNode(w *bufio.Writer, n *Node) error {
+	putUvarint(w, uint64(len(n.items)))
+	for _, item := range n.items {
+		data, err := t.codec.Marshal(item)
+		if err != nil {
+			return err
+		}
+		putUvarint(w, uint64(len(data)))
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+	for _, child := range n.children {
+		if err := t.marshalNode(w, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putUvarint(w io.Writer, x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.Write(b[:binary.PutUvarint(b[:], x)])
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// ReadFrom implements the io.ReaderFrom interface. It replaces the items and
+// the degree of the B-tree with the ones streamed from the r as written by
+// WriteTo, building the nodes as the items are decoded one at a time by the
+// codec set by SetCodec, and returns the number of bytes read. If the r is not
+// an io.ByteReader, it is buffered and may be read past the end of the B-tree.
+func (t *Tree) ReadFrom(r io.Reader) (n int64, err error) {
+	loaded, n, err := t.readFrom(r)
+	if err != nil {
+		return n, err
+	}
+	t.load(loaded)
+	return n, nil
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+type countReader struct {
+	r byteReader
+	n int64
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.n++
+	}
+	return b, err
+}
+
+func (t *Tree) readFrom(r io.Reader) (*Tree, int64, error) {
+	if t.codec == nil {
+		return nil, 0, ErrCodec
+	}
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	cr := &countReader{r: br}
+	version, err := cr.ReadByte()
+	if err != nil {
+		return nil, cr.n, unexpected(err)
+	} else if version != binaryVersion {
+		return nil, cr.n, ErrBinary
+	}
+	var header [3]uint64
+	for i := range header {
+		if header[i], err = binary.ReadUvarint(cr); err != nil {
+			return nil, cr.n, unexpected(err)
+		}
+	}
+	degree, length, height := header[0], header[1], header[2]
+	if degree > MaxDegree || ValidateDegree(int(degree)) != nil || height > 64 {
+		return nil, cr.n, ErrBinary
+	}
+	loaded := &Tree{degree: int(degree), codec: t.codec}
+	if length > 0 {
+		root, err := loaded.unmarshalNode(cr, nil, int(height))
+		if err != nil {
+			return nil, cr.n, err
+		}
+		loaded.root = root
+		loaded.length = root.size
+		loaded.setExtremes()
+	}
+	if uint64(loaded.length) != length || loaded.Validate() != nil {
+		return nil, cr.n, ErrBinary
+	}
+	return loaded, cr.n, nil
+}
+
+func (t *Tree) unmarshalNode(r *countReader, parent *Node, height int) (*Node, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, unexpected(err)
+	} else if count == 0 || count > uint64(t.MaxItems()) {
+		return nil, ErrBinary
+	}
+	n := newNode(t.MaxItems())
+	n.parent = parent
+	var buf bytes.Buffer
+	for i := uint64(0); i < count; i++ {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpected(err)
+		} else if int64(size) < 0 {
+			return nil, ErrBinary
+		}
+		buf.Reset()
+		if _, err = io.CopyN(&buf, r, int64(size)); err != nil {
+			return nil, unexpected(err)
+		}
+		item, err := t.codec.Unmarshal(append([]byte(nil), buf.Bytes()...))
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, ErrBinary
+		}
+		n.items = append(n.items, item)
+	}
+	if height > 0 {
+		for i := uint64(0); i <= count; i++ {
+			child, err := t.unmarshalNode(r, n, height-1)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+	}
+	n.update()
+	return n, nil
+}
+
+// load replaces the items and the degree of the B-tree with the ones of the loaded tree.
+func (t *Tree) load(loaded *Tree) {
+	t.Clear()
+	t.degree, t.root, t.length = loaded.degree, loaded.root, loaded.length
+	t.setExtremes()
+	t.Ascend(func(item Item) bool {
+		t.recordKey(nil, item)
+		return true
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bytes"
+	"encoding"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = &Tree{}
+	_ encoding.BinaryUnmarshaler = &Tree{}
+)
+
+func sameShape(a, b *Node) bool {
+	if len(a.items) != len(b.items) || len(a.children) != len(b.children) || a.size != b.size {
+		return false
+	}
+	for i := range a.items {
+		if a.items[i].Less(b.items[i]) || b.items[i].Less(a.items[i]) {
+			return false
+		}
+	}
+	for i := range a.children {
+		if !sameShape(a.children[i], b.children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBinary(t *testing.T) {
+	for _, degree := range []int{2, 3, 8} {
+		tree := New(degree)
+		if _, err := tree.MarshalBinary(); err != ErrCodec {
+			t.Error(err)
+		}
+		tree.SetCodec(intCodec{})
+		for i := 0; i < 500; i++ {
+			tree.Insert(Int(i * 7 % 500))
+		}
+		for i := 0; i < 500; i += 3 {
+			tree.Delete(Int(i))
+		}
+		data, err := tree.MarshalBinary()
+		if err != nil {
+			t.Error(err)
+		}
+		loaded := New(2)
+		if loaded.UnmarshalBinary(data) != ErrCodec {
+			t.Error("")
+		}
+		loaded.SetCodec(intCodec{})
+		loaded.Insert(Int(1000))
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Error(err)
+		}
+		testTraversal(loaded, t)
+		if loaded.degree != degree || loaded.Length() != tree.Length() || !sameShape(loaded.root, tree.root) {
+			t.Error(loaded.Length())
+		}
+		if clone := loaded.Clone(); clone.codec == nil {
+			t.Error("")
+		}
+		for i := 0; i < len(data); i++ {
+			if err := New(2).UnmarshalBinary(data[:i]); err != ErrCodec {
+				t.Error(err)
+			}
+			broken := New(2)
+			broken.SetCodec(intCodec{})
+			if err := broken.UnmarshalBinary(data[:i]); err == nil {
+				t.Error(i)
+			}
+		}
+		if err := loaded.UnmarshalBinary(append(data, 0)); err != ErrBinary || loaded.Length() != tree.Length() {
+			t.Error(err)
+		}
+		tree.SetCodec(errorCodec{})
+		if _, err := tree.MarshalBinary(); err == nil {
+			t.Error("")
+		}
+		if err := tree.UnmarshalBinary(data); err == nil {
+			t.Error("")
+		}
+	}
+	tree := New(4)
+	tree.SetCodec(intCodec{})
+	data, _ := tree.MarshalBinary()
+	loaded := New(2)
+	loaded.SetCodec(intCodec{})
+	loaded.Insert(Int(1))
+	if err := loaded.UnmarshalBinary(data); err != nil || loaded.Length() != 0 || loaded.degree != 4 {
+		t.Error(err)
+	}
+	data[0] = 0
+	if err := loaded.UnmarshalBinary(data); err != ErrBinary {
+		t.Error(err)
+	}
+}
+
+type shortWriter struct {
+	n int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		n := w.n
+		w.n = 0
+		return n, io.ErrShortWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	tree := New(3)
+	if _, err := tree.WriteTo(ioutil.Discard); err != ErrCodec {
+		t.Error(err)
+	}
+	if _, err := tree.ReadFrom(strings.NewReader("")); err != ErrCodec {
+		t.Error(err)
+	}
+	tree.SetCodec(intCodec{})
+	for i := 0; i < 5000; i++ {
+		tree.Insert(Int(i))
+	}
+	var buf bytes.Buffer
+	n, err := tree.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Error(n, err)
+	}
+	data := buf.Bytes()
+	if marshaled, _ := tree.MarshalBinary(); !bytes.Equal(marshaled, data) {
+		t.Error("")
+	}
+	buf.WriteString("tail")
+	loaded := New(2)
+	loaded.SetCodec(intCodec{})
+	n, err = loaded.ReadFrom(&buf)
+	if err != nil || n != int64(len(data)) || buf.String() != "tail" {
+		t.Error(n, err)
+	}
+	testTraversal(loaded, t)
+	if loaded.Length() != 5000 || !sameShape(loaded.root, tree.root) {
+		t.Error(loaded.Length())
+	}
+	n, err = loaded.ReadFrom(iotest.OneByteReader(bytes.NewReader(data)))
+	if err != nil || n != int64(len(data)) || loaded.Length() != 5000 {
+		t.Error(n, err)
+	}
+	if _, err = loaded.ReadFrom(bytes.NewReader(data[:len(data)/2])); err != io.ErrUnexpectedEOF || loaded.Length() != 5000 {
+		t.Error(err)
+	}
+	if _, err = loaded.ReadFrom(strings.NewReader("")); err != io.ErrUnexpectedEOF {
+		t.Error(err)
+	}
+	if _, err = loaded.ReadFrom(iotest.TimeoutReader(bytes.NewReader(data))); err != iotest.ErrTimeout {
+		t.Error(err)
+	}
+	if n, err = tree.WriteTo(&shortWriter{n: 100}); err != io.ErrShortWrite || n != 100 {
+		t.Error(n, err)
+	}
+}
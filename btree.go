@@ -18,6 +18,46 @@
 //
 package btree
 
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+)
+
+const (
+	// MinDegree is the min degree of a B-tree.
+	MinDegree = 2
+	// MaxDegree is the max degree of a B-tree.
+	MaxDegree = 1 << 16
+)
+
+// ErrDegree is returned when the degree is out of [MinDegree, MaxDegree].
+var ErrDegree = errors.New("bad degree")
+
+// ErrOrder is returned when an item is appended out of order.
+var ErrOrder = errors.New("item out of order")
+
+// ValidateDegree returns ErrDegree if the degree is out of [MinDegree, MaxDegree].
+func ValidateDegree(degree int) error {
+	if degree < MinDegree || degree > MaxDegree {
+		return ErrDegree
+	}
+	return nil
+}
+
+// NodesForItems returns the max number of nodes of a B-tree with the
+// given degree holding n items, when every node holds the min number of items.
+// It panics if the degree is invalid.
+func NodesForItems(n, degree int) int {
+	if ValidateDegree(degree) != nil {
+		panic("bad degree")
+	}
+	if n <= 0 {
+		return 0
+	}
+	return 1 + (n-1)/(degree-1)
+}
+
 // Item represents a value in the tree.
 type Item interface {
 	// Less compares whether the current item is less than the given Item.
@@ -32,6 +72,20 @@ func (a Int) Less(b Item) bool {
 	return a < b.(Int)
 }
 
+// Copier is implemented by items that can return a copy of themselves.
+type Copier interface {
+	// Copy returns a copy of the current item.
+	Copy() Item
+}
+
+// Comparer is implemented by items that can compare themselves with three-way
+// results, which lets the search stop at an equal item with one comparison per probe.
+type Comparer interface {
+	// Compare returns a negative number if the current item is less than the
+	// given Item, zero if they are equal and a positive number otherwise.
+	Compare(than Item) int
+}
+
 // String implements the Item interface for string.
 type String string
 
@@ -42,19 +96,70 @@ func (a String) Less(b Item) bool {
 
 // Tree represents a B-tree.
 type Tree struct {
-	degree int
-	length int
-	root   *Node
+	degree    int
+	length    int
+	root      *Node
+	snapshots map[string]*snapshot
+	listeners *listeners
+	views     *views
+	clock     Clock
+	codec     Codec
+	keys      *keyCheck
+	gens      *generations
+	hash      *merkleHash
+	agg       *Aggregate
+	numeric   bool
+	minLeaf   *Node
+	maxLeaf   *Node
 }
 
 // New returns a new B-tree with the given degree.
 func New(degree int) *Tree {
-	if degree <= 1 {
+	if ValidateDegree(degree) != nil {
 		panic("bad degree")
 	}
 	return &Tree{degree: degree}
 }
 
+// LoadSorted returns a new B-tree with the given degree holding the sorted
+// items. The tree is built bottom-up in linear time with the nodes as full
+// as possible. It panics if the items are not in strictly ascending order.
+func LoadSorted(degree int, sorted []Item) *Tree {
+	return load(degree, sorted, false)
+}
+
+// LoadSortedDesc returns a new B-tree with the given degree holding the items
+// sorted in descending order, built the same as LoadSorted without reversing
+// the items first. It panics if the items are not in strictly descending order.
+func LoadSortedDesc(degree int, sorted []Item) *Tree {
+	return load(degree, sorted, true)
+}
+
+func load(degree int, sorted []Item, desc bool) *Tree {
+	t := New(degree)
+	for i, item := range sorted {
+		if item == nil {
+			panic("nil item being inserted to tree")
+		}
+		if i > 0 && desc && !item.Less(sorted[i-1]) {
+			panic("items not in descending order")
+		} else if i > 0 && !desc && !sorted[i-1].Less(item) {
+			panic("items not in ascending order")
+		}
+	}
+	if len(sorted) == 0 {
+		return t
+	}
+	nodes, seps := pack(sorted, desc, nil, t.MaxItems())
+	for len(nodes) > 1 {
+		nodes, seps = pack(seps, false, nodes, t.MaxItems())
+	}
+	t.root = nodes[0]
+	t.length = len(sorted)
+	t.setExtremes()
+	return t
+}
+
 // Length returns the number of items currently in the B-tree.
 func (t *Tree) Length() int {
 	return t.length
@@ -77,12 +182,28 @@ func (t *Tree) MinItems() int {
 
 // Max returns the max node of the B-tree.
 func (t *Tree) Max() *Node {
-	return t.root.max()
+	return t.maxLeaf
 }
 
 // Min returns the min node of the B-tree.
 func (t *Tree) Min() *Node {
-	return t.root.min()
+	return t.minLeaf
+}
+
+// MaxItem returns the max item of the B-tree.
+func (t *Tree) MaxItem() Item {
+	if t.maxLeaf == nil {
+		return nil
+	}
+	return t.maxLeaf.items[len(t.maxLeaf.items)-1]
+}
+
+// MinItem returns the min item of the B-tree.
+func (t *Tree) MinItem() Item {
+	if t.minLeaf == nil {
+		return nil
+	}
+	return t.minLeaf.items[0]
 }
 
 // Search searches the Item of the B-tree.
@@ -90,7 +211,19 @@ func (t *Tree) Search(item Item) Item {
 	if t.root == nil {
 		return nil
 	}
-	return t.root.search(item)
+	found := t.root.search(item)
+	t.checkKey(found)
+	return found
+}
+
+// Get returns the item of the B-tree equal to the item and whether it was found.
+func (t *Tree) Get(item Item) (Item, bool) {
+	if t.root == nil {
+		return nil, false
+	}
+	found := t.root.search(item)
+	t.checkKey(found)
+	return found, found != nil
 }
 
 // SearchNode searches the node of the B-tree with the item.
@@ -107,22 +240,273 @@ func (t *Tree) SearchIterator(item Item) *Iterator {
 	if t.root == nil {
 		return nil
 	}
-	n, i := t.root.searchNode(item)
-	return n.Iterator(i)
+	return t.root.iterator(item, true)
 }
 
 // Insert inserts the item into the B-tree.
 func (t *Tree) Insert(item Item) {
+	t.insert(item, true)
+}
+
+// ReplaceOrInsert inserts the item into the B-tree and returns the replaced equal item.
+func (t *Tree) ReplaceOrInsert(item Item) (old Item, replaced bool) {
+	old = t.insert(item, true)
+	return old, old != nil
+}
+
+// GetOrInsert returns the existing item equal to the item if present,
+// otherwise it inserts the item into the B-tree and returns it.
+func (t *Tree) GetOrInsert(item Item) (actual Item, loaded bool) {
+	if old := t.insert(item, false); old != nil {
+		return old, true
+	}
+	return item, false
+}
+
+// Update calls the fn with the item of the B-tree equal to the item and whether
+// it exists, and stores the item returned by the fn if the fn returns true.
+// The returned item must be equal to the item. The B-tree is descended once,
+// copying the shared nodes on the path even if the fn declines.
+func (t *Tree) Update(item Item, fn func(old Item, exists bool) (Item, bool)) {
+	if t.root == nil {
+		if updated, ok := fn(nil, false); ok {
+			checkUpdate(item, updated)
+			t.insert(updated, true)
+		}
+		return
+	}
+	n, i, found := t.mutableLocate(item)
+	var old Item
+	if found {
+		old = n.items[i]
+	}
+	updated, ok := fn(old, found)
+	if !ok {
+		return
+	}
+	checkUpdate(item, updated)
+	switch {
+	case found:
+		n.items[i] = updated
+		n.updatePath()
+	case len(n.items) < n.maxItems():
+		n.items.insert(i, updated)
+		n.updatePath()
+		t.length++
+	default:
+		median, right := n.split(updated)
+		n.update()
+		right.update()
+		t.promote(n, median, right)
+		if n == t.maxLeaf {
+			t.maxLeaf = right
+		}
+		t.length++
+	}
+	t.recordKey(old, updated)
+	t.invalidate(updated)
+}
+
+func checkUpdate(item, updated Item) {
+	if updated == nil {
+		panic("nil item being updated to tree")
+	}
+	if item.Less(updated) || updated.Less(item) {
+		panic("updated item not equal to item")
+	}
+}
+
+// InsertIfAbsentBatch inserts the items absent from the B-tree and
+// returns the number of the inserted items.
+func (t *Tree) InsertIfAbsentBatch(items []Item) (added int) {
+	for _, item := range items {
+		if t.insert(item, false) == nil {
+			added++
+		}
+	}
+	return
+}
+
+// InsertBatch inserts the items into the B-tree, replacing the existing equal
+// items. The items are sorted first, so the adjacent items falling into the
+// same leaf are inserted without descending from the root again. Among the
+// equal items of the batch the last one wins.
+func (t *Tree) InsertBatch(items []Item) {
+	sorted := make([]Item, len(items))
+	copy(sorted, items)
+	for _, item := range sorted {
+		if item == nil {
+			panic("nil item being inserted to tree")
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Less(sorted[j])
+	})
+	var leaf *Node
+	var hi Item
+	for i, item := range sorted {
+		if i+1 < len(sorted) && !item.Less(sorted[i+1]) {
+			continue
+		}
+		if leaf != nil && hi != nil && !item.Less(hi) {
+			leaf = nil
+		}
+		if leaf == nil && t.root != nil {
+			if n, _, _ := t.mutableLocate(item); len(n.children) == 0 {
+				leaf, hi = n, n.upperBound()
+			}
+		}
+		if leaf != nil {
+			j, existed := leaf.items.search(item)
+			if existed {
+				t.recordKey(leaf.items[j], item)
+				leaf.items[j] = item
+				leaf.grow(0)
+				t.invalidate(item)
+				continue
+			} else if len(leaf.items) < leaf.maxItems() {
+				leaf.items.insert(j, item)
+				leaf.grow(1)
+				t.length++
+				t.recordKey(nil, item)
+				t.invalidate(item)
+				continue
+			}
+		}
+		t.insert(item, true)
+		leaf = nil
+	}
+}
+
+// MergeSorted merges the sorted items into the B-tree. An item equal to an
+// existing item is replaced by the item returned by the resolve func, which
+// must be equal to both; a nil resolve func keeps the new item. A batch that is
+// large relative to the B-tree is merged in a single linear pass followed by a
+// packed rebuild, while a small batch is merged item by item. Every listener of
+// the merged items is called once.
+func (t *Tree) MergeSorted(sorted []Item, resolve func(old, new Item) Item) {
+	for i, item := range sorted {
+		if item == nil {
+			panic("nil item being inserted to tree")
+		}
+		if i > 0 && !sorted[i-1].Less(item) {
+			panic("items not in ascending order")
+		}
+	}
+	if len(sorted) == 0 {
+		return
+	}
+	merge := func(old, item Item) Item {
+		if resolve == nil {
+			return item
+		}
+		resolved := resolve(old, item)
+		checkUpdate(item, resolved)
+		return resolved
+	}
+	if len(sorted) <= t.length/8 {
+		listeners := t.listeners
+		t.listeners = nil
+		for _, item := range sorted {
+			t.Update(item, func(old Item, found bool) (Item, bool) {
+				if !found {
+					return item, true
+				}
+				return merge(old, item), true
+			})
+		}
+		t.listeners = listeners
+		t.invalidateItems(sorted)
+		return
+	}
+	merged := make([]Item, 0, t.length+len(sorted))
+	i := 0
+	t.root.ascend(func(old Item) bool {
+		for ; i < len(sorted) && sorted[i].Less(old); i++ {
+			merged = append(merged, sorted[i])
+			t.recordKey(nil, sorted[i])
+		}
+		if i < len(sorted) && !old.Less(sorted[i]) {
+			item := merge(old, sorted[i])
+			merged = append(merged, item)
+			t.recordKey(old, item)
+			i++
+			return true
+		}
+		merged = append(merged, old)
+		return true
+	})
+	for ; i < len(sorted); i++ {
+		merged = append(merged, sorted[i])
+		t.recordKey(nil, sorted[i])
+	}
+	t.release()
+	t.root = LoadSorted(t.degree, merged).root
+	t.length = len(merged)
+	t.setExtremes()
+	t.invalidateItems(sorted)
+}
+
+// Append inserts the item, which must not be less than the max item of the
+// B-tree, directly into the rightmost leaf without descending from the root,
+// and replaces the max item if they are equal. A full leaf is split upward
+// along the cached rightmost path. It returns ErrOrder if the item is less
+// than the max item.
+func (t *Tree) Append(item Item) error {
+	if item == nil {
+		panic("nil item being inserted to tree")
+	}
+	if t.root == nil {
+		t.insert(item, true)
+		return nil
+	}
+	max := t.MaxItem()
+	if item.Less(max) {
+		return ErrOrder
+	}
+	t.mutate()
+	leaf := t.maxLeaf
+	var old Item
+	switch {
+	case !max.Less(item):
+		old = max
+		leaf.items[len(leaf.items)-1] = item
+		leaf.grow(0)
+	case len(leaf.items) < leaf.maxItems():
+		leaf.items = append(leaf.items, item)
+		leaf.grow(1)
+		t.length++
+	default:
+		median, right := leaf.split(item)
+		leaf.update()
+		right.update()
+		t.promote(leaf, median, right)
+		t.maxLeaf = right
+		t.length++
+	}
+	t.recordKey(old, item)
+	t.invalidate(item)
+	return nil
+}
+
+// insert inserts the item into the B-tree and returns the existing equal item.
+// The existing item is replaced only if replace is true.
+func (t *Tree) insert(item Item, replace bool) (old Item) {
 	if item == nil {
 		panic("nil item being inserted to tree")
 	}
+	t.mutate()
 	if t.root == nil {
 		t.root = newNode(t.MaxItems())
 		t.root.items = append(t.root.items, item)
+		t.root.update()
+		t.minLeaf, t.maxLeaf = t.root, t.root
 		t.length++
+		t.recordKey(nil, item)
+		t.invalidate(item)
 		return
 	}
-	median, right, ok := t.root.insert(item, false)
+	median, right, old := t.root.insert(item, false, replace)
 	if median != nil {
 		left := t.root
 		t.root = newNode(t.MaxItems())
@@ -130,29 +514,368 @@ func (t *Tree) Insert(item Item) {
 		t.root.children = append(t.root.children, left, right)
 		left.parent = t.root
 		right.parent = t.root
+		t.root.update()
+	}
+	// The min leaf keeps the left half of a split, while the max leaf is
+	// replaced by the right half, which is linked next to it.
+	if m := t.maxLeaf; m.parent != nil {
+		t.maxLeaf = m.parent.children[len(m.parent.children)-1]
 	}
-	if ok {
+	if old == nil {
 		t.length++
 	}
+	if old == nil || replace {
+		t.recordKey(old, item)
+		t.invalidate(item)
+	}
 	return
 }
 
 // Clear removes all items from the B-tree.
 func (t *Tree) Clear() {
-	t.root = nil
-	t.length = 0
+	t.invalidateAll()
+	if t.keys != nil {
+		t.keys.keys = make(map[Item]interface{})
+	}
+	if t.gens != nil {
+		t.gens.entries.Clear()
+	}
+	t.release()
 }
 
 // Delete deletes the node of the B-tree with the item.
 func (t *Tree) Delete(item Item) {
-	var ok bool
-	t.root, ok = t.root.delete(item, -1)
+	t.delete(item, 0, removeItem)
+}
+
+// DeleteItem deletes the item of the B-tree and returns the removed item.
+func (t *Tree) DeleteItem(item Item) (removed Item, ok bool) {
+	removed = t.delete(item, 0, removeItem)
+	return removed, removed != nil
+}
+
+// DeleteMin deletes the min item of the B-tree and returns it.
+func (t *Tree) DeleteMin() (Item, bool) {
+	removed := t.delete(nil, 0, removeMin)
+	return removed, removed != nil
+}
+
+// DeleteMax deletes the max item of the B-tree and returns it.
+func (t *Tree) DeleteMax() (Item, bool) {
+	removed := t.delete(nil, 0, removeMax)
+	return removed, removed != nil
+}
+
+// delete deletes the item of the B-tree selected by the typ and returns it.
+func (t *Tree) delete(item Item, at int, typ toRemove) (removed Item) {
+	if t.root == nil {
+		return nil
+	}
+	t.mutate()
+	t.root, removed = t.root.delete(item, at, typ, -1)
 	if t.root != nil && t.root.parent != nil {
 		t.root.parent = nil
 	}
-	if ok {
+	// The min leaf absorbs its right sibling in a merge, while the max leaf is
+	// absorbed by its left sibling, which leaves it out of its parent.
+	if t.root == nil {
+		t.minLeaf, t.maxLeaf = nil, nil
+	} else if m := t.maxLeaf; m.parent != nil && m.parent.children[len(m.parent.children)-1] != m {
+		t.maxLeaf = t.root.max()
+	}
+	if removed != nil {
 		t.length--
+		t.recordKey(removed, nil)
+		t.invalidate(removed)
 	}
+	return
+}
+
+// DeleteAt deletes the i-th smallest item of the B-tree in a single descent
+// and returns it, or false if i is out of [0, Length()).
+func (t *Tree) DeleteAt(i int) (Item, bool) {
+	if i < 0 || i >= t.length {
+		return nil, false
+	}
+	return t.delete(nil, i, removeAt), true
+}
+
+// DeleteAndNext deletes the item of the B-tree and returns the iterator of the item
+// that now follows it. The bool reports whether the item was deleted.
+func (t *Tree) DeleteAndNext(item Item) (*Iterator, bool) {
+	if t.root == nil {
+		return nil, false
+	}
+	n, i, found := t.root.locate(item)
+	if !found {
+		return t.root.iterator(item, false), false
+	}
+	if len(n.children) == 0 && (n == t.root || len(n.items) > n.minItems()) {
+		n, i, _ = t.mutableLocate(item)
+		removed := n.items[i]
+		n.items.remove(i)
+		n.updatePath()
+		t.length--
+		t.recordKey(removed, nil)
+		t.invalidate(item)
+		if len(n.items) == 0 {
+			t.release()
+			return nil, true
+		}
+		if i < len(n.items) {
+			return n.Iterator(i), true
+		}
+		return n.Iterator(i - 1).Next(), true
+	}
+	t.Delete(item)
+	return t.root.iterator(item, false), true
+}
+
+// Rank returns the number of items of the B-tree less than the item in O(log n) time.
+func (t *Tree) Rank(item Item) int {
+	return t.root.rank(item)
+}
+
+// At returns the i-th smallest item of the B-tree in O(log n) time, and false
+// if i is out of [0, Length()).
+func (t *Tree) At(i int) (Item, bool) {
+	if i < 0 || i >= t.length {
+		return nil, false
+	}
+	return t.root.at(i), true
+}
+
+// CountRange returns the number of items in the range [lo, hi) of the B-tree
+// in O(log n) time. A nil bound leaves the range unbounded on that side.
+func (t *Tree) CountRange(lo, hi Item) int {
+	start, end := 0, t.length
+	if lo != nil {
+		start = t.root.rank(lo)
+	}
+	if hi != nil {
+		end = t.root.rank(hi)
+	}
+	if end < start {
+		return 0
+	}
+	return end - start
+}
+
+// CountPrefix returns the number of String items of the B-tree with the prefix.
+func (t *Tree) CountPrefix(prefix string) int {
+	lo := t.root.rank(String(prefix))
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			hi := prefix[:i] + string([]byte{prefix[i] + 1})
+			return t.root.rank(String(hi)) - lo
+		}
+	}
+	return t.length - lo
+}
+
+// Ascend calls the fn for every item of the B-tree in ascending order until
+// the fn returns false. The stored items are handed to the fn without copying.
+func (t *Tree) Ascend(fn func(item Item) bool) {
+	t.root.ascend(fn)
+}
+
+// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan)
+// of the B-tree in ascending order until the fn returns false.
+func (t *Tree) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool) {
+	t.root.ascendRange(greaterOrEqual, lessThan, fn)
+}
+
+// AscendGreaterOrEqual calls the fn for every item greater than or equal to the pivot
+// of the B-tree in ascending order until the fn returns false.
+func (t *Tree) AscendGreaterOrEqual(pivot Item, fn func(item Item) bool) {
+	t.root.ascendRange(pivot, nil, fn)
+}
+
+// Descend calls the fn for every item of the B-tree in descending order until
+// the fn returns false.
+func (t *Tree) Descend(fn func(item Item) bool) {
+	t.root.descendRange(nil, nil, fn)
+}
+
+// DescendLessOrEqual calls the fn for every item less than or equal to the pivot
+// of the B-tree in descending order until the fn returns false.
+func (t *Tree) DescendLessOrEqual(pivot Item, fn func(item Item) bool) {
+	t.root.descendRange(pivot, nil, fn)
+}
+
+// DescendRange calls the fn for every item in the range (greaterThan, lessOrEqual]
+// of the B-tree in descending order until the fn returns false.
+func (t *Tree) DescendRange(lessOrEqual, greaterThan Item, fn func(item Item) bool) {
+	t.root.descendRange(lessOrEqual, greaterThan, fn)
+}
+
+// AscendCopied calls the fn for every item of the B-tree in ascending order
+// until the fn returns false. Items implementing the Copier interface are
+// copied before being handed to the fn, so the fn may mutate them without
+// affecting the B-tree. Other scans hand out the stored items without copying.
+func (t *Tree) AscendCopied(fn func(item Item) bool) {
+	t.root.ascend(func(item Item) bool {
+		if c, ok := item.(Copier); ok {
+			return fn(c.Copy())
+		}
+		return fn(item)
+	})
+}
+
+// AscendPairs calls the fn for every pair of adjacent items of the B-tree
+// in ascending order until the fn returns false.
+func (t *Tree) AscendPairs(fn func(prev, cur Item) bool) {
+	var prev Item
+	t.root.ascend(func(item Item) bool {
+		if prev == nil {
+			prev = item
+			return true
+		}
+		last := prev
+		prev = item
+		return fn(last, item)
+	})
+}
+
+// AscendWindow calls the fn for every window of k adjacent items of the B-tree
+// in ascending order until the fn returns false. The window is reused between calls.
+func (t *Tree) AscendWindow(k int, fn func(window []Item) bool) {
+	if k <= 0 {
+		panic("bad window size")
+	}
+	buf := make([]Item, 0, k*2)
+	t.root.ascend(func(item Item) bool {
+		if len(buf) == cap(buf) {
+			copy(buf, buf[len(buf)-k+1:])
+			for i := k - 1; i < len(buf); i++ {
+				buf[i] = nil
+			}
+			buf = buf[:k-1]
+		}
+		buf = append(buf, item)
+		if len(buf) < k {
+			return true
+		}
+		return fn(buf[len(buf)-k:])
+	})
+}
+
+// MoveRange moves the items in the range [lo, hi) from the src B-tree to the
+// dst B-tree, replacing the equal items, and returns the number of the moved
+// items. The range is cut out of the src and joined into the dst, so the
+// subtrees inside it move without copying, unless the trees differ in degree
+// or either caches the merkle hashes or the aggregates of the nodes.
+func MoveRange(src, dst *Tree, lo, hi Item) int {
+	if src.degree != dst.degree || src.agg != nil || dst.agg != nil || src.hash != nil || dst.hash != nil {
+		var moved []Item
+		src.AscendRange(lo, hi, func(item Item) bool {
+			moved = append(moved, item)
+			return true
+		})
+		for _, item := range moved {
+			src.Delete(item)
+			dst.Insert(item)
+		}
+		return len(moved)
+	}
+	n := src.removeRange(lo, hi)
+	moved := n.Size()
+	dst.insertRange(n, lo, hi)
+	return moved
+}
+
+// Clone returns a lazy copy of the B-tree sharing the nodes in O(1) time. Every
+// node counts its references, and a mutation of either tree copies only the
+// shared nodes on its path from the root, so the trees diverge in O(log n)
+// copies per mutation. The first mutation after a clone also copies the paths
+// to the min and max leaves, so that their parent links lead to the root of
+// the mutated tree. The snapshots and the listeners are not cloned.
+func (t *Tree) Clone() *Tree {
+	if t.root != nil {
+		atomic.AddInt32(&t.root.refs, 1)
+	}
+	return &Tree{degree: t.degree, length: t.length, root: t.root, hash: t.hash,
+		agg: t.agg, numeric: t.numeric, clock: t.clock, codec: t.codec,
+		minLeaf: t.minLeaf, maxLeaf: t.maxLeaf}
+}
+
+// mutate copies the root if it is shared with other trees before a mutation,
+// along with the paths to the min and max leaves. The other shared nodes are
+// copied as the mutation descends to them.
+func (t *Tree) mutate() {
+	if t.root != nil && t.root.shared() {
+		root := t.root.cloneExtremes(nil, true, true)
+		t.root.release()
+		t.root = root
+		t.setExtremes()
+	}
+}
+
+// mutableLocate is locate copying the shared nodes on the path, so the returned
+// node and its ancestors can be mutated in place. The B-tree must not be empty.
+func (t *Tree) mutableLocate(item Item) (node *Node, index int, found bool) {
+	t.mutate()
+	n := t.root
+	for {
+		i, existed := n.items.search(item)
+		if existed || len(n.children) == 0 {
+			return n, i, existed
+		}
+		n = n.mutableChild(i)
+	}
+}
+
+// promote links the median and the right node split from the n, whose path
+// from the root must be mutable, into the ancestors of the n, splitting them in
+// turn and growing a new root if the root splits, and updates the path.
+func (t *Tree) promote(n *Node, median Item, right *Node) {
+	for median != nil {
+		p := n.parent
+		if p == nil {
+			t.root = newNode(t.MaxItems())
+			t.root.items = append(t.root.items, median)
+			t.root.children = append(t.root.children, n, right)
+			n.parent = t.root
+			right.parent = t.root
+			t.root.update()
+			return
+		}
+		median, right = p.link(median, right)
+		p.update()
+		if right != nil {
+			right.update()
+		}
+		n = p
+	}
+	n.parent.updatePath()
+}
+
+// setExtremes copies the shared nodes on the paths to the min and max leaves,
+// so that the parent links on the paths lead to the root, and caches the
+// leaves. The root must not be shared.
+func (t *Tree) setExtremes() {
+	t.minLeaf, t.maxLeaf = nil, nil
+	if t.root == nil {
+		return
+	}
+	n := t.root
+	for len(n.children) > 0 {
+		n = n.mutableChild(0)
+	}
+	t.minLeaf = n
+	n = t.root
+	for len(n.children) > 0 {
+		n = n.mutableChild(len(n.children) - 1)
+	}
+	t.maxLeaf = n
+}
+
+// release drops the reference of the B-tree to its root and leaves it empty, so
+// the other trees sharing the nodes no longer copy them.
+func (t *Tree) release() {
+	t.root.release()
+	t.root, t.length = nil, 0
+	t.minLeaf, t.maxLeaf = nil, nil
 }
 
 // Node represents a node in the B-tree.
@@ -160,10 +883,15 @@ type Node struct {
 	items    items
 	children children
 	parent   *Node
+	refs     int32
+	orphan   int32
+	size     int
+	hash     atomic.Value
+	agg      atomic.Value
 }
 
 func newNode(maxItems int) *Node {
-	return &Node{items: make([]Item, 0, maxItems), children: make([]*Node, 0, maxItems+1)}
+	return &Node{items: make([]Item, 0, maxItems), children: make([]*Node, 0, maxItems+1), refs: 1}
 }
 
 // Items returns the items of this node.
@@ -182,20 +910,63 @@ func (n *Node) Children() []*Node {
 	return n.children
 }
 
-// Parent returns the parent node.
+// Parent returns the parent node. It returns nil for a node shared by the
+// clones of a B-tree, whose parent link may lead into another clone, except
+// on the paths to the min and max leaves, which every tree keeps unshared.
 func (n *Node) Parent() *Node {
-	if n == nil {
+	if n == nil || !n.linked() {
 		return nil
 	}
 	return n.parent
 }
 
-// Iterator returns the iterator with the item index of this node.
+// Depth returns the number of levels of the subtree rooted at this node.
+func (n *Node) Depth() int {
+	depth := 0
+	for n != nil {
+		depth++
+		if len(n.children) == 0 {
+			break
+		}
+		n = n.children[0]
+	}
+	return depth
+}
+
+// Size returns the number of items in the subtree rooted at this node.
+func (n *Node) Size() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// Fill returns the ratio of the number of items to the max number of items of this node.
+func (n *Node) Fill() float64 {
+	if n == nil {
+		return 0
+	}
+	return float64(len(n.items)) / float64(n.maxItems())
+}
+
+// Iterator returns the iterator with the item index of this node. The path to
+// the root is taken from the parent links as far as Parent returns them, so the
+// iterator of a node below a node shared by the clones of a B-tree stops at the
+// end of the subtree of the shared node. The iterators of the min and max
+// nodes and the iterators returned by the B-tree, such as by SearchIterator,
+// cover the whole tree.
 func (n *Node) Iterator(index int) *Iterator {
 	if n == nil {
 		return nil
 	}
-	return &Iterator{node: n, index: index, parentIndex: n.parentIndex()}
+	i := &Iterator{node: n, index: index}
+	for c := n; c.parent != nil && c.linked(); c = c.parent {
+		i.path = append(i.path, step{node: c.parent, index: c.parentIndex()})
+	}
+	for l, r := 0, len(i.path)-1; l < r; l, r = l+1, r-1 {
+		i.path[l], i.path[r] = i.path[r], i.path[l]
+	}
+	return i
 }
 
 // MinIterator returns the iterator with the min item index of this node.
@@ -242,6 +1013,186 @@ func (n *Node) minItems() int {
 	return cap(n.items) / 2
 }
 
+// shared reports whether the node is referenced by more than one parent or
+// tree, in which case it must be copied before it is mutated.
+func (n *Node) shared() bool {
+	return atomic.LoadInt32(&n.refs) > 1
+}
+
+// linked reports whether the parent link of the node leads to its only parent.
+// The link of a shared node may lead into another tree, and the link of an
+// orphan into a parent that no longer holds it.
+func (n *Node) linked() bool {
+	return !n.shared() && atomic.LoadInt32(&n.orphan) == 0
+}
+
+// release drops a reference to the node, and the references of the node to its
+// children once it is no longer referenced, marking the children linked to it
+// as orphans.
+func (n *Node) release() {
+	if n != nil && atomic.AddInt32(&n.refs, -1) == 0 {
+		for _, child := range n.children {
+			if child.parent == n {
+				atomic.StoreInt32(&child.orphan, 1)
+			}
+			child.release()
+		}
+	}
+}
+
+// clone returns a copy of the node under the parent sharing the children.
+func (n *Node) clone(parent *Node) *Node {
+	return n.cloneExtremes(parent, false, false)
+}
+
+// cloneExtremes is clone copying the first child if min is true and the last
+// child if max is true down to the leaves, so the nodes on the paths to the
+// min and max leaves of the other trees are never shared, not even briefly,
+// and keep their parent links trusted by the readers of the trees.
+func (n *Node) cloneExtremes(parent *Node, min, max bool) *Node {
+	c := newNode(n.maxItems())
+	c.items = append(c.items, n.items...)
+	c.children = append(c.children, n.children...)
+	last := len(c.children) - 1
+	for i, child := range c.children {
+		if i == 0 && min || i == last && max {
+			c.children[i] = child.cloneExtremes(c, i == 0 && min, i == last && max)
+		} else {
+			atomic.AddInt32(&child.refs, 1)
+		}
+	}
+	c.parent, c.size = parent, n.size
+	if h := n.cachedHash(); h != nil {
+		c.hash.Store(h)
+	}
+	if a := n.cachedAggregate(); a != nil {
+		c.agg.Store(a)
+	}
+	return c
+}
+
+// mutableChild returns the i-th child of this node, which must be mutable,
+// replacing the child with a copy first if it is shared. The replaced child is
+// marked as an orphan if its parent link leads to this node.
+func (n *Node) mutableChild(i int) *Node {
+	c := n.children[i]
+	if !c.shared() {
+		n.adopt(c)
+		return c
+	}
+	copied := c.clone(n)
+	n.children[i] = copied
+	if c.parent == n {
+		atomic.StoreInt32(&c.orphan, 1)
+	}
+	c.release()
+	return copied
+}
+
+// adopt links the child to this node. A shared child keeps its parent link,
+// which may still be read through the other trees sharing it, and is marked as
+// an orphan if the link leads elsewhere.
+func (n *Node) adopt(child *Node) {
+	if child.shared() {
+		if child.parent != n {
+			atomic.StoreInt32(&child.orphan, 1)
+		}
+		return
+	}
+	child.parent = n
+	atomic.StoreInt32(&child.orphan, 0)
+}
+
+func (n *Node) update() {
+	size := len(n.items)
+	for _, child := range n.children {
+		size += child.size
+	}
+	n.size = size
+	n.resetCaches()
+}
+
+// resetCaches drops the content hash and the aggregate cached in this node.
+func (n *Node) resetCaches() {
+	if n.cachedHash() != nil {
+		n.hash.Store((*hashCache)(nil))
+	}
+	if n.cachedAggregate() != nil {
+		n.agg.Store((*aggregateCache)(nil))
+	}
+}
+
+func (n *Node) rank(item Item) (rank int) {
+	for n != nil {
+		i, existed := n.items.search(item)
+		rank += i
+		if len(n.children) == 0 {
+			return
+		}
+		for _, child := range n.children[:i] {
+			rank += child.size
+		}
+		if existed {
+			return rank + n.children[i].size
+		}
+		n = n.children[i]
+	}
+	return
+}
+
+func (n *Node) at(i int) Item {
+	for len(n.children) > 0 {
+		j := 0
+		for ; i >= n.children[j].size; j++ {
+			i -= n.children[j].size
+			if i == 0 {
+				return n.items[j]
+			}
+			i--
+		}
+		n = n.children[j]
+	}
+	return n.items[i]
+}
+
+// updatePath updates this node and its ancestors.
+func (n *Node) updatePath() {
+	for ; n != nil; n = n.parent {
+		n.update()
+	}
+}
+
+// grow adds the delta to the sizes of this node and its ancestors.
+func (n *Node) grow(delta int) {
+	for ; n != nil; n = n.parent {
+		n.size += delta
+		n.resetCaches()
+	}
+}
+
+// upperBound returns the least item of the ancestors greater than the items
+// of this node, or nil if there is none.
+func (n *Node) upperBound() Item {
+	for p := n.parent; p != nil; n, p = p, p.parent {
+		if i := n.parentIndex(); i < len(p.items) {
+			return p.items[i]
+		}
+	}
+	return nil
+}
+
+// locate returns the node with the index of the item if found,
+// otherwise the leaf with the index where the item would be inserted.
+func (n *Node) locate(item Item) (node *Node, index int, found bool) {
+	for {
+		i, existed := n.items.search(item)
+		if existed || len(n.children) == 0 {
+			return n, i, existed
+		}
+		n = n.children[i]
+	}
+}
+
 func (n *Node) search(item Item) Item {
 	i, existed := n.items.search(item)
 	if existed {
@@ -264,55 +1215,107 @@ func (n *Node) searchNode(item Item) (*Node, int) {
 	return nil, -1
 }
 
-func (n *Node) insert(item Item, nonleaf bool) (median Item, right *Node, ok bool) {
+func (n *Node) insert(item Item, nonleaf, replace bool) (median Item, right *Node, old Item) {
 	i, existed := n.items.search(item)
 	if existed {
-		n.items[i] = item
-		ok = false
+		old = n.items[i]
+		if replace {
+			n.items[i] = item
+			n.update()
+		}
 		return
 	}
 	if len(n.children) == 0 || nonleaf {
 		if len(n.items) < n.maxItems() {
 			n.items.insert(i, item)
-			ok = true
+			n.update()
 			return
 		}
-		return n.split(item)
+		median, right = n.split(item)
+		n.update()
+		right.update()
+		return
 	}
-	median, right, ok = n.children[i].insert(item, false)
+	median, right, old = n.mutableChild(i).insert(item, false, replace)
 	if median != nil {
-		m := median
-		r := right
-		median, right, ok = n.insert(median, true)
-		index, found := n.items.search(m)
+		median, right = n.link(median, right)
+	}
+	n.update()
+	if right != nil {
+		right.update()
+	}
+	return
+}
+
+// link inserts the median and the right node split from a child of this node,
+// splitting this node in turn if it is full.
+func (n *Node) link(median Item, r *Node) (Item, *Node) {
+	m := median
+	median, right, _ := n.insert(median, true, false)
+	index, found := n.items.search(m)
+	if found {
+		n.children.insert(index+1, r)
+		r.parent = n
+	} else if right != nil {
+		index, found := right.items.search(m)
 		if found {
-			n.children.insert(index+1, r)
-			r.parent = n
-			return
-		}
-		if right != nil {
-			index, found := right.items.search(m)
-			if found {
-				right.children.insert(index+1, r)
-				r.parent = right
-			}
+			right.children.insert(index+1, r)
+			r.parent = right
 		}
 	}
-	return
+	return median, right
 }
 
-func (n *Node) delete(item Item, parentIndex int) (root *Node, ok bool) {
+// toRemove selects the item to remove by delete.
+type toRemove int
+
+const (
+	removeItem toRemove = iota
+	removeMin
+	removeMax
+	removeAt
+)
+
+// delete removes the item selected by the typ, which is the item equal to the
+// item for removeItem, or the at-th smallest item for removeAt.
+func (n *Node) delete(item Item, at int, typ toRemove, parentIndex int) (root *Node, removed Item) {
 	if n == nil {
-		return nil, false
+		return nil, nil
+	}
+	var i int
+	var existed bool
+	switch typ {
+	case removeMin:
+		existed = len(n.children) == 0
+	case removeMax:
+		i = len(n.items)
+		if len(n.children) == 0 {
+			i, existed = i-1, true
+		}
+	case removeAt:
+		if len(n.children) == 0 {
+			i, existed = at, true
+			break
+		}
+		for ; i < len(n.items) && at >= n.children[i].size; i++ {
+			at -= n.children[i].size
+			if at == 0 {
+				existed = true
+				break
+			}
+			at--
+		}
+	default:
+		i, existed = n.items.search(item)
 	}
-	i, existed := n.items.search(item)
 	if existed {
+		removed = n.items[i]
 		if len(n.children) == 0 {
 			n.items.remove(i)
+			n.update()
 			if len(n.items) > 0 {
 				root = n
 			}
-			ok = true
 			if n.parent != nil && len(n.items) < n.minItems() {
 				n.rebalance(parentIndex, false)
 			}
@@ -324,16 +1327,26 @@ func (n *Node) delete(item Item, parentIndex int) (root *Node, ok bool) {
 			newSeparator := leftMax.items[len(leftMax.items)-1]
 			n.items[i] = newSeparator
 			item = newSeparator
+			if typ == removeAt {
+				typ = removeMax
+			}
 		} else {
 			newSeparator := rightMin.items[0]
 			n.items[i] = newSeparator
 			item = newSeparator
 			i++
+			if typ == removeAt {
+				typ = removeMin
+			}
 		}
 	}
 	root = n
 	if len(n.children) > i {
-		_, ok = n.children[i].delete(item, i)
+		_, r := n.mutableChild(i).delete(item, at, typ, i)
+		if !existed {
+			removed = r
+		}
+		n.update()
 		if n.parent == nil {
 			if len(n.items) == 0 {
 				if len(n.children) > 0 {
@@ -384,47 +1397,52 @@ func (n *Node) leftSiblingItems(parentIndex int) int {
 func (n *Node) rotateLeft(parentIndex int, nonleaf bool) {
 	p := n.parent
 	n.items.insert(len(n.items), p.items[parentIndex])
-	rightSibling := p.children[parentIndex+1]
+	rightSibling := p.mutableChild(parentIndex + 1)
 	p.items[parentIndex] = rightSibling.items[0]
 	rightSibling.items.remove(0)
 	if nonleaf {
 		n.children.insert(len(n.children), rightSibling.children[0])
-		n.children[len(n.children)-1].parent = n
+		n.adopt(n.children[len(n.children)-1])
 		rightSibling.children.remove(0)
 	}
+	n.update()
+	rightSibling.update()
 }
 
 func (n *Node) rotateRight(parentIndex int, nonleaf bool) {
 	p := n.parent
 	n.items.insert(0, p.items[parentIndex-1])
-	leftSibling := p.children[parentIndex-1]
+	leftSibling := p.mutableChild(parentIndex - 1)
 	p.items[parentIndex-1] = leftSibling.items[len(leftSibling.items)-1]
 	leftSibling.items.remove(len(leftSibling.items) - 1)
 	if nonleaf {
 		n.children.insert(0, leftSibling.children[len(leftSibling.children)-1])
-		n.children[0].parent = n
+		n.adopt(n.children[0])
 		leftSibling.children.remove(len(leftSibling.children) - 1)
 	}
+	n.update()
+	leftSibling.update()
 }
 
 func (n *Node) mergeLeft(parentIndex int, nonleaf bool) {
 	p := n.parent
 	n.items.insert(len(n.items), p.items[parentIndex])
-	right := p.children[parentIndex+1]
+	right := p.mutableChild(parentIndex + 1)
 	n.items.appendRight(right.items)
 	p.items.remove(parentIndex)
 	p.children.remove(parentIndex + 1)
 	if nonleaf {
 		n.children.appendRight(right.children)
 		for _, v := range right.children {
-			v.parent = n
+			n.adopt(v)
 		}
 	}
+	n.update()
 }
 
 func (n *Node) mergeRight(parentIndex int, nonleaf bool) {
 	p := n.parent
-	leftSibling := p.children[parentIndex-1]
+	leftSibling := p.mutableChild(parentIndex - 1)
 	leftSibling.items.insert(len(leftSibling.items), p.items[parentIndex-1])
 	leftSibling.items.appendRight(n.items)
 	p.items.remove(parentIndex - 1)
@@ -432,9 +1450,10 @@ func (n *Node) mergeRight(parentIndex int, nonleaf bool) {
 	if nonleaf {
 		leftSibling.children.appendRight(n.children)
 		for _, v := range n.children {
-			v.parent = leftSibling
+			leftSibling.adopt(v)
 		}
 	}
+	leftSibling.update()
 }
 
 func (n *Node) min() *Node {
@@ -457,8 +1476,94 @@ func (n *Node) max() *Node {
 	return n
 }
 
-func (n *Node) split(item Item) (median Item, right *Node, ok bool) {
-	ok = true
+func (n *Node) ascend(fn func(item Item) bool) bool {
+	if n == nil {
+		return true
+	}
+	for i, item := range n.items {
+		if len(n.children) > 0 && !n.children[i].ascend(fn) {
+			return false
+		}
+		if !fn(item) {
+			return false
+		}
+	}
+	if len(n.children) > 0 {
+		return n.children[len(n.children)-1].ascend(fn)
+	}
+	return true
+}
+
+// ascendRange calls the fn for every item in the range [lo, hi) of the subtree
+// in ascending order. A nil bound leaves the range unbounded on that side.
+func (n *Node) ascendRange(lo, hi Item, fn func(item Item) bool) bool {
+	if n == nil {
+		return true
+	}
+	start, found := 0, false
+	if lo != nil {
+		start, found = n.items.search(lo)
+	}
+	for i := start; i < len(n.items); i++ {
+		if len(n.children) > 0 && !(i == start && found) {
+			bound := lo
+			if i > start {
+				bound = nil
+			}
+			if !n.children[i].ascendRange(bound, hi, fn) {
+				return false
+			}
+		}
+		item := n.items[i]
+		if hi != nil && !item.Less(hi) {
+			return false
+		}
+		if !fn(item) {
+			return false
+		}
+	}
+	if len(n.children) > 0 {
+		bound := lo
+		if start < len(n.items) {
+			bound = nil
+		}
+		return n.children[len(n.items)].ascendRange(bound, hi, fn)
+	}
+	return true
+}
+
+// descendRange calls the fn for every item in the range (gt, le] of the subtree
+// in descending order. A nil bound leaves the range unbounded on that side.
+func (n *Node) descendRange(le, gt Item, fn func(item Item) bool) bool {
+	if n == nil {
+		return true
+	}
+	i, found := len(n.items), false
+	if le != nil {
+		i, found = n.items.search(le)
+	}
+	if !found {
+		if len(n.children) > 0 && !n.children[i].descendRange(le, gt, fn) {
+			return false
+		}
+		i--
+	}
+	for ; i >= 0; i-- {
+		item := n.items[i]
+		if gt != nil && !gt.Less(item) {
+			return false
+		}
+		if !fn(item) {
+			return false
+		}
+		if len(n.children) > 0 && !n.children[i].descendRange(nil, gt, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *Node) split(item Item) (median Item, right *Node) {
 	i := n.minItems()
 	median = n.items[i]
 	right = newNode(n.maxItems())
@@ -469,7 +1574,7 @@ func (n *Node) split(item Item) (median Item, right *Node, ok bool) {
 		n.children = n.children[:i+1]
 	}
 	for _, v := range right.children {
-		v.parent = right
+		right.adopt(v)
 	}
 	if item.Less(median) {
 		index, _ := n.items.search(item)
@@ -481,14 +1586,125 @@ func (n *Node) split(item Item) (median Item, right *Node, ok bool) {
 	return
 }
 
-// Iterator represents an iterator in the B-tree.
+// pack packs the items with the children if any into the fewest nodes holding
+// about equal numbers of items, and returns the nodes with the items separating
+// them. The items in descending order are packed from the end.
+func pack(items []Item, desc bool, children []*Node, maxItems int) (nodes []*Node, seps []Item) {
+	count := (len(items) + maxItems + 1) / (maxItems + 1)
+	total := len(items) - (count - 1)
+	nodes = make([]*Node, 0, count)
+	seps = make([]Item, 0, count-1)
+	next := func() (item Item) {
+		if desc {
+			item, items = items[len(items)-1], items[:len(items)-1]
+		} else {
+			item, items = items[0], items[1:]
+		}
+		return
+	}
+	for i := 0; i < count; i++ {
+		size := total / count
+		if i < total%count {
+			size++
+		}
+		n := newNode(maxItems)
+		if desc {
+			for j := 0; j < size; j++ {
+				n.items = append(n.items, next())
+			}
+		} else {
+			n.items = append(n.items, items[:size]...)
+			items = items[size:]
+		}
+		if children != nil {
+			n.children = append(n.children, children[:size+1]...)
+			children = children[size+1:]
+			for _, child := range n.children {
+				n.adopt(child)
+			}
+		}
+		n.update()
+		nodes = append(nodes, n)
+		if i < count-1 {
+			seps = append(seps, next())
+		}
+	}
+	return
+}
+
+// Iterator represents an iterator in the B-tree. It holds the path from the
+// root to its node, so it does not follow the parent links.
 type Iterator struct {
-	index       int
-	parentIndex int
-	node        *Node
+	index int
+	node  *Node
+	path  []step
+}
+
+// step represents an ancestor on the path of an iterator with the index of the
+// child on the path.
+type step struct {
+	node  *Node
+	index int
+}
+
+// iterator returns the iterator of the item equal to the item if exact, or else
+// of the least item not less than the item, descending from this node.
+func (n *Node) iterator(item Item, exact bool) *Iterator {
+	i := &Iterator{}
+	depth := 0
+	for n != nil {
+		index, existed := n.items.search(item)
+		if existed || !exact && index < len(n.items) {
+			i.node, i.index, depth = n, index, len(i.path)
+		}
+		if existed || len(n.children) == 0 {
+			break
+		}
+		i.path = append(i.path, step{node: n, index: index})
+		n = n.children[index]
+	}
+	if i.node == nil {
+		return nil
+	}
+	i.path = i.path[:depth]
+	return i
+}
+
+// minIterator returns the iterator of the min item of the subtree rooted at this node.
+func (n *Node) minIterator() *Iterator {
+	if n == nil {
+		return nil
+	}
+	i := &Iterator{}
+	return i.descend(n, 0, false)
 }
 
-// Item returns the item of this iterator.
+// maxIterator returns the iterator of the max item of the subtree rooted at this node.
+func (n *Node) maxIterator() *Iterator {
+	if n == nil {
+		return nil
+	}
+	i := &Iterator{}
+	return i.descend(n, len(n.children)-1, true)
+}
+
+// descend moves the iterator down from the index-th child of the n, or from the
+// n itself if it is a leaf, to the min item, or to the max item if max is true.
+func (i *Iterator) descend(n *Node, index int, max bool) *Iterator {
+	for len(n.children) > 0 {
+		i.path = append(i.path, step{node: n, index: index})
+		n = n.children[index]
+		if index = 0; max {
+			index = len(n.children) - 1
+		}
+	}
+	if max {
+		return i.reset(n, len(n.items)-1)
+	}
+	return i.reset(n, 0)
+}
+
+// Item returns the item of this iterator. The stored item is returned without copying.
 func (i *Iterator) Item() Item {
 	if i == nil {
 		return nil
@@ -501,7 +1717,7 @@ func (i *Iterator) Clone() *Iterator {
 	if i == nil {
 		return nil
 	}
-	return &Iterator{node: i.node, index: i.index, parentIndex: i.parentIndex}
+	return &Iterator{node: i.node, index: i.index, path: append([]step(nil), i.path...)}
 }
 
 func (i *Iterator) reset(n *Node, index int) *Iterator {
@@ -509,7 +1725,6 @@ func (i *Iterator) reset(n *Node, index int) *Iterator {
 		return nil
 	}
 	i.index = index
-	i.parentIndex = n.parentIndex()
 	i.node = n
 	return i
 }
@@ -521,23 +1736,17 @@ func (i *Iterator) Last() (last *Iterator) {
 	}
 	n := i.node
 	if len(n.children) > 0 {
-		max := n.children[i.index].max()
-		return i.reset(max, len(max.items)-1)
+		return i.descend(n, i.index, true)
 	}
 	if i.index > 0 {
 		i.index--
 		return i
 	}
-	left := n
-	parentIndex := i.parentIndex
-	p := n.parent
-	for p != nil && parentIndex == 0 {
-		left = p
-		parentIndex = p.parentIndex()
-		p = left.parent
-	}
-	if parentIndex > 0 {
-		return i.reset(p, parentIndex-1)
+	for d := len(i.path) - 1; d >= 0; d-- {
+		if s := i.path[d]; s.index > 0 {
+			i.path = i.path[:d]
+			return i.reset(s.node, s.index-1)
+		}
 	}
 	return
 }
@@ -549,23 +1758,17 @@ func (i *Iterator) Next() (next *Iterator) {
 	}
 	n := i.node
 	if len(n.children) > 0 && i.index < len(n.items) {
-		min := n.children[i.index+1].min()
-		return i.reset(min, 0)
+		return i.descend(n, i.index+1, false)
 	}
 	if i.index < len(i.node.items)-1 {
 		i.index++
 		return i
 	}
-	right := n
-	parentIndex := i.parentIndex
-	p := n.parent
-	for p != nil && parentIndex == len(p.children)-1 {
-		right = p
-		parentIndex = p.parentIndex()
-		p = right.parent
-	}
-	if parentIndex > -1 && parentIndex < len(p.items) {
-		return i.reset(p, parentIndex)
+	for d := len(i.path) - 1; d >= 0; d-- {
+		if s := i.path[d]; s.index < len(s.node.items) {
+			i.path = i.path[:d]
+			return i.reset(s.node, s.index)
+		}
 	}
 	return
 }
@@ -584,6 +1787,14 @@ func (s *items) appendRight(i items) {
 	*s = append(*s, i...)
 }
 
+// truncate truncates the items at the index, clearing the rest for the GC.
+func (s *items) truncate(index int) {
+	for i := index; i < len(*s); i++ {
+		(*s)[i] = nil
+	}
+	*s = (*s)[:index]
+}
+
 func (s *items) remove(index int) {
 	copy((*s)[index:], (*s)[index+1:])
 	(*s)[len(*s)-1] = nil
@@ -592,6 +1803,19 @@ func (s *items) remove(index int) {
 
 func (s items) search(item Item) (index int, ok bool) {
 	i, j := 0, len(s)
+	if c, is := item.(Comparer); is {
+		for i < j {
+			h := int(uint(i+j) >> 1)
+			if r := c.Compare(s[h]); r == 0 {
+				return h, true
+			} else if r > 0 {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		return i, false
+	}
 	for i < j {
 		h := int(uint(i+j) >> 1)
 		if !item.Less(s[h]) {
@@ -620,6 +1844,14 @@ func (s *children) appendRight(i children) {
 	*s = append(*s, i...)
 }
 
+// truncate truncates the children at the index, clearing the rest for the GC.
+func (s *children) truncate(index int) {
+	for i := index; i < len(*s); i++ {
+		(*s)[i] = nil
+	}
+	*s = (*s)[:index]
+}
+
 func (s *children) remove(index int) {
 	copy((*s)[index:], (*s)[index+1:])
 	(*s)[len(*s)-1] = nil
@@ -4,6 +4,8 @@
 package btree
 
 import (
+	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -129,6 +131,27 @@ func testTraversal(tree *Tree, t *testing.T) {
 		t.Error(tree.Length(), count)
 	}
 	traverse(tree.Root(), t)
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
+	if tree.Min() != tree.Root().min() || tree.Max() != tree.Root().max() {
+		t.Error("")
+	}
+	testIteratorAscend(tree, t)
+	testIteratorDescend(tree, t)
+}
+
+// testShared is testTraversal for a tree that shares or has shared nodes with
+// clones, whose parent links may lead into the other clones.
+func testShared(tree *Tree, t *testing.T) {
+	count := 0
+	testLength(tree.Root(), &count)
+	if tree.Length() != count {
+		t.Error(tree.Length(), count)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
 	testIteratorAscend(tree, t)
 	testIteratorDescend(tree, t)
 }
@@ -144,10 +167,16 @@ func testLength(node *Node, count *int) {
 
 func traverse(node *Node, t *testing.T) {
 	if node != nil {
+		size := len(node.items)
 		for _, child := range node.children {
 			if child.parent != node {
 				t.Error("")
 			}
+			traverse(child, t)
+			size += child.Size()
+		}
+		if node.Size() != size {
+			t.Error(node.Size(), size)
 		}
 	}
 }
@@ -156,6 +185,10 @@ func testIteratorAscend(tree *Tree, t *testing.T) {
 	iter := tree.Min().MinIterator()
 	item := iter.Item()
 	next := iter.Next()
+	count := 0
+	if iter != nil {
+		count++
+	}
 	for iter != nil && next != nil {
 		if !item.Less(next.Item()) {
 			t.Error(item, next.Item())
@@ -163,6 +196,10 @@ func testIteratorAscend(tree *Tree, t *testing.T) {
 		iter = next
 		item = iter.Item()
 		next = iter.Next()
+		count++
+	}
+	if count != tree.Length() {
+		t.Error(count, tree.Length())
 	}
 }
 
@@ -170,12 +207,21 @@ func testIteratorDescend(tree *Tree, t *testing.T) {
 	iter := tree.Max().MaxIterator()
 	item := iter.Item()
 	last := iter.Last()
+	count := 0
+	if iter != nil {
+		count++
+	}
 	for iter != nil && last != nil {
 		if !last.Item().Less(item) {
 			t.Error(last.Item(), item)
 		}
 		iter = last
+		item = iter.Item()
 		last = iter.Last()
+		count++
+	}
+	if count != tree.Length() {
+		t.Error(count, tree.Length())
 	}
 }
 
@@ -342,3 +388,1320 @@ func TestReplaceItem(t *testing.T) {
 		}
 	}
 }
+
+type pointer struct {
+	value int
+}
+
+func (a *pointer) Less(b Item) bool {
+	return a.value < b.(*pointer).value
+}
+
+func (a *pointer) Copy() Item {
+	c := *a
+	return &c
+}
+
+func TestAscendCopied(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 64; i++ {
+		tree.Insert(&pointer{i})
+	}
+	count := 0
+	tree.AscendCopied(func(item Item) bool {
+		p := item.(*pointer)
+		if p.value != count {
+			t.Error(p.value, count)
+		}
+		p.value = -1
+		count++
+		return true
+	})
+	if count != 64 {
+		t.Error(count)
+	}
+	for i := 0; i < 64; i++ {
+		if tree.Search(&pointer{i}) == nil {
+			t.Error(i)
+		}
+	}
+	count = 0
+	tree.AscendCopied(func(item Item) bool {
+		count++
+		return count < 8
+	})
+	if count != 8 {
+		t.Error(count)
+	}
+	ints := New(2)
+	for i := 0; i < 8; i++ {
+		ints.Insert(Int(i))
+	}
+	count = 0
+	ints.AscendCopied(func(item Item) bool {
+		if item.(Int) != Int(count) {
+			t.Error(item, count)
+		}
+		count++
+		return true
+	})
+	New(2).AscendCopied(func(item Item) bool {
+		t.Error("")
+		return true
+	})
+}
+
+func TestDeleteAndNext(t *testing.T) {
+	for d := 2; d < 6; d++ {
+		tree := New(d)
+		n := 256
+		for i := 0; i < n; i++ {
+			tree.Insert(Int(i * 2))
+		}
+		iter, ok := tree.DeleteAndNext(Int(-1))
+		if ok || iter == nil || iter.Item().(Int) != 0 {
+			t.Error(ok, iter)
+		}
+		iter, ok = tree.DeleteAndNext(Int(1))
+		if ok || iter == nil || iter.Item().(Int) != 2 {
+			t.Error(ok, iter)
+		}
+		iter = tree.Min().MinIterator()
+		for i := 0; iter != nil; i++ {
+			item := iter.Item()
+			if item.(Int) != Int(i*2) {
+				t.Error(item, i*2)
+			}
+			iter, ok = tree.DeleteAndNext(item)
+			if !ok {
+				t.Error(item)
+			}
+			testTraversal(tree, t)
+			if tree.Length() != n-i-1 {
+				t.Error(tree.Length(), n-i-1)
+			}
+		}
+		if tree.Length() != 0 || tree.Root() != nil {
+			t.Error(tree.Length())
+		}
+		if iter, ok := tree.DeleteAndNext(Int(0)); ok || iter != nil {
+			t.Error(ok, iter)
+		}
+	}
+}
+
+func TestNodeStats(t *testing.T) {
+	tree := New(2)
+	if tree.Root().Depth() != 0 || tree.Root().Size() != 0 || tree.Root().Fill() != 0 {
+		t.Error("")
+	}
+	tree.Insert(Int(0))
+	if tree.Root().Depth() != 1 || tree.Root().Size() != 1 {
+		t.Error("")
+	}
+	if fill := tree.Root().Fill(); fill != 1.0/3 {
+		t.Error(fill)
+	}
+	for i := 1; i < 1024; i++ {
+		tree.Insert(Int(i))
+	}
+	root := tree.Root()
+	if root.Size() != 1024 {
+		t.Error(root.Size())
+	}
+	depth := root.Depth()
+	for _, child := range root.Children() {
+		if child.Depth() != depth-1 {
+			t.Error(child.Depth(), depth-1)
+		}
+		if child.Fill() <= 0 || child.Fill() > 1 {
+			t.Error(child.Fill())
+		}
+	}
+	if tree.Min().Depth() != 1 {
+		t.Error(tree.Min().Depth())
+	}
+}
+
+func TestRank(t *testing.T) {
+	tree := New(2)
+	if tree.Rank(Int(0)) != 0 {
+		t.Error("")
+	}
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(Int(i))
+	}
+	for i := -1; i < 102; i++ {
+		want := (i + 1) / 2
+		if i > 100 {
+			want = 50
+		} else if i < 0 {
+			want = 0
+		}
+		if r := tree.Rank(Int(i)); r != want {
+			t.Error(i, r, want)
+		}
+	}
+}
+
+func TestTreeAt(t *testing.T) {
+	tree := New(3)
+	if _, ok := tree.At(0); ok {
+		t.Error("")
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i * 2))
+	}
+	for i := 0; i < 100; i++ {
+		if item, ok := tree.At(i); !ok || item.(Int) != Int(i*2) {
+			t.Error(i, item)
+		}
+	}
+	if _, ok := tree.At(-1); ok {
+		t.Error("")
+	}
+	if _, ok := tree.At(100); ok {
+		t.Error("")
+	}
+}
+
+func TestDeleteAt(t *testing.T) {
+	for degree := 2; degree < 5; degree++ {
+		tree := New(degree)
+		if _, ok := tree.DeleteAt(0); ok {
+			t.Error("")
+		}
+		var model []int
+		for i := 0; i < 200; i++ {
+			tree.Insert(Int(i))
+			model = append(model, i)
+		}
+		clone := tree.Clone()
+		for k := 0; len(model) > 0; k++ {
+			i := k * 37 % len(model)
+			item, ok := tree.DeleteAt(i)
+			if !ok || item.(Int) != Int(model[i]) {
+				t.Error(i, item, model[i])
+			}
+			model = append(model[:i], model[i+1:]...)
+			if k%20 == 0 {
+				testShared(tree, t)
+			}
+		}
+		testShared(tree, t)
+		testShared(clone, t)
+		if tree.Length() != 0 || clone.Length() != 200 {
+			t.Error(tree.Length())
+		}
+		if _, ok := clone.DeleteAt(200); ok {
+			t.Error("")
+		}
+		if _, ok := clone.DeleteAt(-1); ok {
+			t.Error("")
+		}
+	}
+}
+
+func TestCountRange(t *testing.T) {
+	tree := New(2)
+	if tree.CountRange(nil, nil) != 0 {
+		t.Error("")
+	}
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(Int(i))
+	}
+	for lo := -1; lo < 102; lo++ {
+		for hi := lo - 1; hi < 102; hi += 3 {
+			count := 0
+			tree.AscendRange(Int(lo), Int(hi), func(item Item) bool {
+				count++
+				return true
+			})
+			if n := tree.CountRange(Int(lo), Int(hi)); n != count {
+				t.Error(lo, hi, n, count)
+			}
+		}
+	}
+	if tree.CountRange(nil, Int(10)) != 5 || tree.CountRange(Int(90), nil) != 5 || tree.CountRange(nil, nil) != 50 {
+		t.Error("")
+	}
+}
+
+func TestCountPrefix(t *testing.T) {
+	tree := New(2)
+	if tree.CountPrefix("a") != 0 {
+		t.Error("")
+	}
+	prefixes := []string{"", "a", "ab", "b", "\xff", "a\xff"}
+	for _, prefix := range prefixes {
+		for i := 0; i < 32; i++ {
+			tree.Insert(String(prefix + string(rune('a'+i%26)) + string(rune('0'+i/26))))
+		}
+	}
+	for _, prefix := range prefixes {
+		count := 0
+		tree.AscendCopied(func(item Item) bool {
+			if strings.HasPrefix(string(item.(String)), prefix) {
+				count++
+			}
+			return true
+		})
+		if tree.CountPrefix(prefix) != count {
+			t.Error(prefix, tree.CountPrefix(prefix), count)
+		}
+	}
+	if tree.CountPrefix("") != tree.Length() {
+		t.Error("")
+	}
+	if tree.CountPrefix("zz") != 0 {
+		t.Error("")
+	}
+}
+
+func TestAscendPairs(t *testing.T) {
+	tree := New(3)
+	tree.AscendPairs(func(prev, cur Item) bool {
+		t.Error("")
+		return true
+	})
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	count := 0
+	tree.AscendPairs(func(prev, cur Item) bool {
+		if prev.(Int) != Int(count) || cur.(Int) != Int(count+1) {
+			t.Error(prev, cur)
+		}
+		count++
+		return true
+	})
+	if count != 99 {
+		t.Error(count)
+	}
+	count = 0
+	tree.AscendPairs(func(prev, cur Item) bool {
+		count++
+		return count < 10
+	})
+	if count != 10 {
+		t.Error(count)
+	}
+}
+
+func TestAscendWindow(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	for k := 1; k < 8; k++ {
+		count := 0
+		tree.AscendWindow(k, func(window []Item) bool {
+			if len(window) != k {
+				t.Error(len(window), k)
+			}
+			for j, item := range window {
+				if item.(Int) != Int(count+j) {
+					t.Error(item, count+j)
+				}
+			}
+			count++
+			return true
+		})
+		if count != 100-k+1 {
+			t.Error(count, 100-k+1)
+		}
+	}
+	count := 0
+	tree.AscendWindow(200, func(window []Item) bool {
+		count++
+		return true
+	})
+	if count != 0 {
+		t.Error(count)
+	}
+	count = 0
+	tree.AscendWindow(3, func(window []Item) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Error(count)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	tree.AscendWindow(0, nil)
+}
+
+func TestInsertIfAbsentBatch(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 64; i += 2 {
+		tree.Insert(&pointer{i})
+	}
+	batch := make([]Item, 0, 64)
+	for i := 0; i < 64; i++ {
+		batch = append(batch, &pointer{i})
+	}
+	stored := tree.Search(&pointer{0})
+	if added := tree.InsertIfAbsentBatch(batch); added != 32 {
+		t.Error(added)
+	}
+	testTraversal(tree, t)
+	if tree.Length() != 64 || tree.Search(&pointer{0}) != stored {
+		t.Error("")
+	}
+	if added := tree.InsertIfAbsentBatch(batch); added != 0 {
+		t.Error(added)
+	}
+}
+
+func TestLoadSorted(t *testing.T) {
+	for degree := 2; degree < 6; degree++ {
+		for n := 0; n < 200; n++ {
+			sorted := make([]Item, n)
+			for i := range sorted {
+				sorted[i] = Int(i)
+			}
+			tree := LoadSorted(degree, sorted)
+			testTraversal(tree, t)
+			if tree.Length() != n {
+				t.Error(tree.Length(), n)
+			}
+			for i := 0; i < n; i++ {
+				if tree.Search(Int(i)) == nil {
+					t.Error(i)
+				}
+			}
+			tree.Insert(Int(n))
+			tree.Delete(Int(0))
+			testTraversal(tree, t)
+		}
+	}
+	sorted := make([]Item, 1000)
+	for i := range sorted {
+		sorted[i] = Int(i)
+	}
+	if s := LoadSorted(4, sorted).Stats(); s.Fill < 0.95 {
+		t.Error(s.Fill)
+	}
+	for _, items := range [][]Item{{Int(1), Int(0)}, {Int(0), Int(0)}, {nil}} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			LoadSorted(2, items)
+		}()
+	}
+}
+
+func TestLoadSortedDesc(t *testing.T) {
+	for degree := 2; degree < 6; degree++ {
+		for n := 0; n < 200; n++ {
+			sorted := make([]Item, n)
+			desc := make([]Item, n)
+			for i := range sorted {
+				sorted[i] = Int(i)
+				desc[i] = Int(n - 1 - i)
+			}
+			tree := LoadSortedDesc(degree, desc)
+			testTraversal(tree, t)
+			if tree.Length() != n || n > 0 && !sameShape(tree.root, LoadSorted(degree, sorted).root) {
+				t.Error(tree.Length(), n)
+			}
+			if n > 0 && desc[0].(Int) != Int(n-1) {
+				t.Error(desc)
+			}
+		}
+	}
+	for _, items := range [][]Item{{Int(0), Int(1)}, {Int(0), Int(0)}, {nil}} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			LoadSortedDesc(2, items)
+		}()
+	}
+}
+
+func TestInsertBatch(t *testing.T) {
+	for _, degree := range []int{2, 3, 8} {
+		tree := New(degree)
+		tree.InsertBatch(nil)
+		for i := 0; i < 100; i += 3 {
+			tree.Insert(&pointer{i})
+		}
+		clone := tree.Clone()
+		var fired int
+		tree.OnRangeInvalidate(&pointer{0}, &pointer{10}, func() { fired++ })
+		batch := make([]Item, 0, 300)
+		for i := 0; i < 300; i++ {
+			batch = append(batch, &pointer{i * 7 % 300 % 150})
+		}
+		tree.InsertBatch(batch)
+		testTraversal(tree, t)
+		testTraversal(clone, t)
+		if tree.Length() != 150 || clone.Length() != 34 || fired == 0 {
+			t.Error(tree.Length(), clone.Length(), fired)
+		}
+		next := 0
+		tree.Ascend(func(item Item) bool {
+			if item.(*pointer).value != next {
+				t.Error(item, next)
+			}
+			next++
+			return true
+		})
+		last := make(map[int]Item)
+		for _, item := range batch {
+			last[item.(*pointer).value] = item
+		}
+		for i := 0; i < 150; i++ {
+			if tree.Search(&pointer{i}) != last[i] {
+				t.Error(i)
+			}
+		}
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	for _, size := range []int{5, 60} {
+		tree := New(3)
+		for i := 0; i < 100; i += 2 {
+			tree.Insert(tagged{key: i, tag: "a"})
+		}
+		clone := tree.Clone()
+		var fired int
+		tree.OnRangeInvalidate(tagged{key: 0}, tagged{key: 1000}, func() { fired++ })
+		batch := make([]Item, 0, size)
+		for i := 0; i < size; i++ {
+			batch = append(batch, tagged{key: i * 3, tag: "b"})
+		}
+		tree.MergeSorted(batch, func(old, new Item) Item {
+			return tagged{key: new.(tagged).key, tag: old.(tagged).tag + new.(tagged).tag}
+		})
+		testShared(tree, t)
+		testShared(clone, t)
+		added := 0
+		for i := 0; i < size; i++ {
+			if i*3%2 != 0 || i*3 >= 100 {
+				added++
+			}
+		}
+		if tree.Length() != 50+added || clone.Length() != 50 || fired != 1 {
+			t.Error(size, tree.Length(), fired)
+		}
+		for i := 0; i < 3*size || i < 100; i++ {
+			item := tree.Search(tagged{key: i})
+			var tag string
+			if item != nil {
+				tag = item.(tagged).tag
+			}
+			var want string
+			if i%2 == 0 && i < 100 {
+				want += "a"
+			}
+			if i%3 == 0 && i < 3*size {
+				want += "b"
+			}
+			if tag != want {
+				t.Error(size, i, tag, want)
+			}
+		}
+		tree.MergeSorted(batch[:1], nil)
+		if tree.Search(tagged{key: 0}).(tagged).tag != "b" {
+			t.Error(tree.Search(tagged{key: 0}))
+		}
+		tree.MergeSorted(nil, nil)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	New(2).MergeSorted([]Item{Int(2), Int(1)}, nil)
+}
+
+func TestAppend(t *testing.T) {
+	for _, degree := range []int{2, 3, 8} {
+		tree := New(degree)
+		var clone *Tree
+		for i := 0; i < 200; i++ {
+			if err := tree.Append(&pointer{i}); err != nil {
+				t.Error(err)
+			}
+			if i == 100 {
+				clone = tree.Clone()
+			}
+		}
+		testShared(tree, t)
+		testShared(clone, t)
+		if tree.Length() != 200 || clone.Length() != 101 {
+			t.Error(tree.Length(), clone.Length())
+		}
+		if tree.Append(&pointer{150}) != ErrOrder || tree.Length() != 200 {
+			t.Error("")
+		}
+		last := &pointer{199}
+		if tree.Append(last) != nil || tree.Length() != 200 || tree.MaxItem() != last {
+			t.Error(tree.MaxItem())
+		}
+		next := 0
+		tree.Ascend(func(item Item) bool {
+			if item.(*pointer).value != next {
+				t.Error(item, next)
+			}
+			next++
+			return true
+		})
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	New(2).Append(nil)
+}
+
+func TestAscend(t *testing.T) {
+	tree := New(2)
+	tree.Ascend(func(item Item) bool {
+		t.Error("")
+		return true
+	})
+	n := 100
+	for i := n - 1; i >= 0; i-- {
+		tree.Insert(Int(i))
+	}
+	count := 0
+	tree.Ascend(func(item Item) bool {
+		if item.(Int) != Int(count) {
+			t.Error(item, count)
+		}
+		count++
+		return true
+	})
+	if count != n {
+		t.Error(count)
+	}
+	for stop := 1; stop < n; stop++ {
+		count = 0
+		tree.Ascend(func(item Item) bool {
+			count++
+			return count < stop
+		})
+		if count != stop {
+			t.Error(count, stop)
+		}
+	}
+}
+
+func TestAscendRangeBounds(t *testing.T) {
+	for d := 2; d < 5; d++ {
+		tree := New(d)
+		for i := 0; i < 200; i += 2 {
+			tree.Insert(Int(i))
+		}
+		for lo := -2; lo < 203; lo++ {
+			for _, hi := range []int{lo, lo + 1, lo + 7, lo + 50, 300} {
+				next := lo
+				if next < 0 {
+					next = 0
+				}
+				next += next % 2
+				tree.root.ascendRange(Int(lo), Int(hi), func(item Item) bool {
+					if item.(Int) != Int(next) {
+						t.Error(lo, hi, item, next)
+					}
+					next += 2
+					return true
+				})
+				if next < hi && next < 200 {
+					t.Error(lo, hi, next)
+				}
+			}
+		}
+	}
+}
+
+func TestDescend(t *testing.T) {
+	tree := New(2)
+	tree.Descend(func(item Item) bool {
+		t.Error("")
+		return true
+	})
+	n := 100
+	for i := 0; i < n; i++ {
+		tree.Insert(Int(i))
+	}
+	count := n
+	tree.Descend(func(item Item) bool {
+		count--
+		if item.(Int) != Int(count) {
+			t.Error(item, count)
+		}
+		return true
+	})
+	if count != 0 {
+		t.Error(count)
+	}
+	for stop := 1; stop < n; stop++ {
+		count = 0
+		tree.Descend(func(item Item) bool {
+			count++
+			return count < stop
+		})
+		if count != stop {
+			t.Error(count, stop)
+		}
+	}
+}
+
+func TestDescendRangeBounds(t *testing.T) {
+	for d := 2; d < 5; d++ {
+		tree := New(d)
+		for i := 0; i < 200; i += 2 {
+			tree.Insert(Int(i))
+		}
+		for le := -2; le < 203; le++ {
+			for _, gt := range []int{le, le - 1, le - 7, le - 50, -100} {
+				next := le
+				if next > 198 {
+					next = 198
+				}
+				next -= (next + 200) % 2
+				tree.root.descendRange(Int(le), Int(gt), func(item Item) bool {
+					if item.(Int) != Int(next) {
+						t.Error(le, gt, item, next)
+					}
+					next -= 2
+					return true
+				})
+				if next > gt && next >= 0 {
+					t.Error(le, gt, next)
+				}
+			}
+		}
+	}
+}
+
+func TestAscendRange(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	next := 10
+	tree.AscendRange(Int(10), Int(20), func(item Item) bool {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+		return true
+	})
+	if next != 20 {
+		t.Error(next)
+	}
+	count := 0
+	tree.AscendRange(Int(10), Int(20), func(item Item) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Error(count)
+	}
+	tree.AscendRange(Int(20), Int(10), func(item Item) bool {
+		t.Error(item)
+		return true
+	})
+}
+
+func TestDescendRange(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	next := 20
+	tree.DescendRange(Int(20), Int(10), func(item Item) bool {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next--
+		return true
+	})
+	if next != 10 {
+		t.Error(next)
+	}
+	count := 0
+	tree.DescendRange(Int(20), Int(10), func(item Item) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Error(count)
+	}
+	tree.DescendRange(Int(10), Int(20), func(item Item) bool {
+		t.Error(item)
+		return true
+	})
+}
+
+func TestAscendGreaterOrEqual(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(Int(i))
+	}
+	for _, pivot := range []int{-1, 0, 11, 50, 98} {
+		next := pivot + (pivot+2)%2
+		if next < 0 {
+			next = 0
+		}
+		tree.AscendGreaterOrEqual(Int(pivot), func(item Item) bool {
+			if item.(Int) != Int(next) {
+				t.Error(pivot, item, next)
+			}
+			next += 2
+			return true
+		})
+		if next != 100 {
+			t.Error(pivot, next)
+		}
+	}
+	tree.AscendGreaterOrEqual(Int(99), func(item Item) bool {
+		t.Error(item)
+		return true
+	})
+}
+
+func TestDescendLessOrEqual(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(Int(i))
+	}
+	for _, pivot := range []int{0, 11, 50, 98, 120} {
+		next := pivot - pivot%2
+		if next > 98 {
+			next = 98
+		}
+		tree.DescendLessOrEqual(Int(pivot), func(item Item) bool {
+			if item.(Int) != Int(next) {
+				t.Error(pivot, item, next)
+			}
+			next -= 2
+			return true
+		})
+		if next != -2 {
+			t.Error(pivot, next)
+		}
+	}
+	tree.DescendLessOrEqual(Int(-1), func(item Item) bool {
+		t.Error(item)
+		return true
+	})
+}
+
+func TestDeleteMin(t *testing.T) {
+	for d := 2; d < 6; d++ {
+		tree := New(d)
+		if item, ok := tree.DeleteMin(); ok || item != nil {
+			t.Error(item)
+		}
+		n := 300
+		for i := n - 1; i >= 0; i-- {
+			tree.Insert(Int(i))
+		}
+		for i := 0; i < n; i++ {
+			item, ok := tree.DeleteMin()
+			if !ok || item.(Int) != Int(i) {
+				t.Error(item, i)
+			}
+			testTraversal(tree, t)
+			if tree.Length() != n-i-1 {
+				t.Error(tree.Length())
+			}
+		}
+		if tree.Root() != nil {
+			t.Error("")
+		}
+	}
+}
+
+func TestDeleteMax(t *testing.T) {
+	for d := 2; d < 6; d++ {
+		tree := New(d)
+		if item, ok := tree.DeleteMax(); ok || item != nil {
+			t.Error(item)
+		}
+		n := 300
+		for i := 0; i < n; i++ {
+			tree.Insert(Int(i))
+		}
+		for i := n - 1; i >= 0; i-- {
+			item, ok := tree.DeleteMax()
+			if !ok || item.(Int) != Int(i) {
+				t.Error(item, i)
+			}
+			testTraversal(tree, t)
+			if tree.Length() != i {
+				t.Error(tree.Length())
+			}
+		}
+		if tree.Root() != nil {
+			t.Error("")
+		}
+	}
+}
+
+func TestValidateDegree(t *testing.T) {
+	if ValidateDegree(MinDegree) != nil || ValidateDegree(MaxDegree) != nil {
+		t.Error("")
+	}
+	if ValidateDegree(MinDegree-1) != ErrDegree || ValidateDegree(MaxDegree+1) != ErrDegree {
+		t.Error("")
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	New(MaxDegree + 1)
+}
+
+func TestNodesForItems(t *testing.T) {
+	if NodesForItems(0, 2) != 0 || NodesForItems(1, 2) != 1 || NodesForItems(10, 2) != 10 || NodesForItems(10, 3) != 5 {
+		t.Error("")
+	}
+	for d := 2; d < 6; d++ {
+		for n := 1; n < 300; n += 7 {
+			tree := New(d)
+			for i := 0; i < n; i++ {
+				tree.Insert(Int(i))
+			}
+			if nodes := countNodes(tree.Root()); nodes > NodesForItems(n, d) {
+				t.Error(d, n, nodes, NodesForItems(n, d))
+			}
+		}
+	}
+	for _, d := range []int{1, 0, -1} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error(d)
+				}
+			}()
+			NodesForItems(10, d)
+		}()
+	}
+}
+
+func countNodes(n *Node) int {
+	count := 1
+	for _, child := range n.Children() {
+		count += countNodes(child)
+	}
+	return count
+}
+
+func TestReplaceOrInsert(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		if old, replaced := tree.ReplaceOrInsert(&pointer{i}); replaced || old != nil {
+			t.Error(old)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		stored := tree.Search(&pointer{i})
+		item := &pointer{i}
+		if old, replaced := tree.ReplaceOrInsert(item); !replaced || old != stored {
+			t.Error(old)
+		}
+		if tree.Search(&pointer{i}) != item {
+			t.Error("")
+		}
+	}
+	testTraversal(tree, t)
+	if tree.Length() != 100 {
+		t.Error(tree.Length())
+	}
+}
+
+func TestDeleteItem(t *testing.T) {
+	tree := New(2)
+	if removed, ok := tree.DeleteItem(&pointer{0}); ok || removed != nil {
+		t.Error(removed)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(&pointer{i})
+	}
+	for i := 0; i < 100; i++ {
+		stored := tree.Search(&pointer{i})
+		if removed, ok := tree.DeleteItem(&pointer{i}); !ok || removed != stored {
+			t.Error(removed)
+		}
+		if removed, ok := tree.DeleteItem(&pointer{i}); ok || removed != nil {
+			t.Error(removed)
+		}
+		testTraversal(tree, t)
+	}
+}
+
+func TestMoveRange(t *testing.T) {
+	src := New(2)
+	dst := New(3)
+	for i := 0; i < 100; i++ {
+		src.Insert(Int(i))
+	}
+	dst.Insert(Int(15))
+	if moved := MoveRange(src, dst, Int(10), Int(20)); moved != 10 {
+		t.Error(moved)
+	}
+	testTraversal(src, t)
+	testTraversal(dst, t)
+	if src.Length() != 90 || dst.Length() != 10 || src.Search(Int(10)) != nil || dst.Search(Int(19)) == nil {
+		t.Error(src.Length(), dst.Length())
+	}
+	if moved := MoveRange(src, dst, Int(10), Int(20)); moved != 0 {
+		t.Error(moved)
+	}
+}
+
+func TestMoveRangeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, degree := range []int{2, 3, 4} {
+		for round := 0; round < 200; round++ {
+			src, dst := New(degree), New(degree)
+			if round%4 == 0 {
+				dst = New(degree + 1)
+			}
+			srcModel, dstModel := map[int]bool{}, map[int]bool{}
+			for i, n := 0, r.Intn(400); i < n; i++ {
+				k := r.Intn(1000)
+				src.Insert(Int(k))
+				srcModel[k] = true
+			}
+			for i, n := 0, r.Intn(400); i < n; i++ {
+				k := r.Intn(1000)
+				dst.Insert(Int(k))
+				dstModel[k] = true
+			}
+			srcClone, dstClone := src.Clone(), dst.Clone()
+			var lo, hi Item
+			a, b := r.Intn(1000), r.Intn(1000)
+			if a > b {
+				a, b = b, a
+			}
+			if r.Intn(4) > 0 {
+				lo = Int(a)
+			}
+			if r.Intn(4) > 0 {
+				hi = Int(b)
+			}
+			var fired, quiet int
+			dst.OnRangeInvalidate(Int(a), Int(a+1), func() { fired++ })
+			dst.OnRangeInvalidate(Int(2000), Int(3000), func() { quiet++ })
+			want, touched := 0, false
+			for k := range srcModel {
+				if (lo == nil || k >= a) && (hi == nil || k < b) {
+					delete(srcModel, k)
+					dstModel[k] = true
+					want++
+					touched = touched || k == a
+				}
+			}
+			if moved := MoveRange(src, dst, lo, hi); moved != want {
+				t.Error(degree, round, moved, want)
+			}
+			if touched && fired != 1 || !touched && fired != 0 || quiet != 0 {
+				t.Error(degree, round, fired, quiet)
+			}
+			for _, c := range []struct {
+				tree  *Tree
+				model map[int]bool
+			}{{src, srcModel}, {dst, dstModel}} {
+				if err := c.tree.Validate(); err != nil || c.tree.Length() != len(c.model) {
+					t.Fatal(degree, round, err, c.tree.Length(), len(c.model))
+				}
+				c.tree.Ascend(func(item Item) bool {
+					if !c.model[int(item.(Int))] {
+						t.Error(degree, round, item)
+					}
+					return true
+				})
+			}
+			if err := srcClone.Validate(); err != nil {
+				t.Fatal(degree, round, err)
+			}
+			if err := dstClone.Validate(); err != nil {
+				t.Fatal(degree, round, err)
+			}
+		}
+	}
+}
+
+func TestMoveRangeNodes(t *testing.T) {
+	src, dst := New(3), New(3)
+	for i := 0; i < 100000; i++ {
+		src.Insert(Int(i))
+		dst.Insert(Int(i + 200000))
+	}
+	old := map[*Node]bool{}
+	testWalk(src.Root(), func(n *Node) { old[n] = true })
+	testWalk(dst.Root(), func(n *Node) { old[n] = true })
+	if moved := MoveRange(src, dst, Int(1234), Int(98765)); moved != 98765-1234 {
+		t.Error(moved)
+	}
+	if err := dst.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	created, height := 0, dst.Root().Depth()
+	testWalk(dst.Root(), func(n *Node) {
+		if !old[n] {
+			created++
+		}
+	})
+	if created > 4*height {
+		t.Error(created, height)
+	}
+}
+
+func TestMoveRangeKeys(t *testing.T) {
+	src, dst := New(3), New(3)
+	for i := 0; i < 1000; i++ {
+		src.Insert(&pointer{i})
+	}
+	for i := 0; i < 1000; i += 7 {
+		dst.Insert(&pointer{i})
+	}
+	key := func(item Item) interface{} {
+		return item.(*pointer).value
+	}
+	dst.EnableKeyCheck(key)
+	dst.EnableGenerations()
+	if moved := MoveRange(src, dst, &pointer{100}, &pointer{900}); moved != 800 {
+		t.Error(moved)
+	}
+	if len(dst.keys.keys) != dst.Length() || dst.gens.entries.Length() != dst.Length() {
+		t.Error(len(dst.keys.keys), dst.gens.entries.Length(), dst.Length())
+	}
+	if _, gen, ok := dst.GetWithGeneration(&pointer{105}); !ok || gen == 0 {
+		t.Error(gen)
+	}
+	if err := dst.CheckKeys(); err != nil {
+		t.Error(err)
+	}
+	if err := dst.Validate(); err != nil || dst.Length() != 800+100/7+1+(1000-900)/7 {
+		t.Error(err, dst.Length())
+	}
+}
+
+func TestGetOrInsert(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		item := &pointer{i}
+		if actual, loaded := tree.GetOrInsert(item); loaded || actual != item {
+			t.Error(actual)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		stored := tree.Search(&pointer{i})
+		if actual, loaded := tree.GetOrInsert(&pointer{i}); !loaded || actual != stored {
+			t.Error(actual)
+		}
+	}
+	testTraversal(tree, t)
+	if tree.Length() != 100 {
+		t.Error(tree.Length())
+	}
+}
+
+func TestGet(t *testing.T) {
+	tree := New(2)
+	if item, ok := tree.Get(Int(0)); ok || item != nil {
+		t.Error(item)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	for i := 0; i < 100; i++ {
+		if item, ok := tree.Get(Int(i)); !ok || item.(Int) != Int(i) {
+			t.Error(item, i)
+		}
+	}
+	if item, ok := tree.Get(Int(100)); ok || item != nil {
+		t.Error(item)
+	}
+}
+
+type counter struct {
+	key   int
+	count int
+}
+
+func (a *counter) Less(b Item) bool {
+	return a.key < b.(*counter).key
+}
+
+var comparisons int
+
+type compared int
+
+func (a compared) Less(b Item) bool {
+	comparisons++
+	return a < b.(compared)
+}
+
+func TestUpdateDescent(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(compared(i * 2))
+	}
+	for i := 0; i < 2000; i++ {
+		comparisons = 0
+		clone := tree.Clone()
+		clone.Insert(compared(i))
+		insert := comparisons
+		clone.release()
+		comparisons = 0
+		tree.Update(compared(i), func(old Item, exists bool) (Item, bool) {
+			return compared(i), true
+		})
+		if comparisons > insert+2 {
+			t.Error(i, comparisons, insert)
+		}
+	}
+	testTraversal(tree, t)
+}
+
+func TestAppendDescent(t *testing.T) {
+	tree := New(3)
+	comparisons = 0
+	for i := 0; i < 10000; i++ {
+		if i%1000 == 0 {
+			tree.Clone()
+		}
+		if err := tree.Append(compared(i)); err != nil {
+			t.Error(err)
+		}
+	}
+	if comparisons > 10000*8 {
+		t.Error(comparisons)
+	}
+	testShared(tree, t)
+}
+
+func TestUpdate(t *testing.T) {
+	for d := 2; d < 5; d++ {
+		tree := New(d)
+		incr := func(key int) func(old Item, exists bool) (Item, bool) {
+			return func(old Item, exists bool) (Item, bool) {
+				if !exists {
+					return &counter{key: key, count: 1}, true
+				}
+				return &counter{key: key, count: old.(*counter).count + 1}, true
+			}
+		}
+		for j := 0; j < 3; j++ {
+			for i := 0; i < 100; i++ {
+				tree.Update(&counter{key: i}, incr(i))
+				testTraversal(tree, t)
+			}
+		}
+		if tree.Length() != 100 {
+			t.Error(tree.Length())
+		}
+		tree.Ascend(func(item Item) bool {
+			if item.(*counter).count != 3 {
+				t.Error(item)
+			}
+			return true
+		})
+		tree.Update(&counter{key: 200}, func(old Item, exists bool) (Item, bool) {
+			if exists || old != nil {
+				t.Error(old)
+			}
+			return nil, false
+		})
+		if tree.Length() != 100 {
+			t.Error(tree.Length())
+		}
+		clone := tree.Clone()
+		clone.Update(&counter{key: 0}, incr(0))
+		if item := tree.Search(&counter{key: 0}); item.(*counter).count != 3 {
+			t.Error(item)
+		}
+		if item := clone.Search(&counter{key: 0}); item.(*counter).count != 4 {
+			t.Error(item)
+		}
+	}
+	for _, fn := range []func(){
+		func() {
+			New(2).Update(Int(0), func(old Item, exists bool) (Item, bool) { return Int(1), true })
+		},
+		func() {
+			New(2).Update(Int(0), func(old Item, exists bool) (Item, bool) { return nil, true })
+		},
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestMinMaxItem(t *testing.T) {
+	tree := New(2)
+	if tree.MinItem() != nil || tree.MaxItem() != nil {
+		t.Error("")
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+		tree.Insert(Int(-i))
+		if tree.MinItem().(Int) != Int(-i) || tree.MaxItem().(Int) != Int(i) {
+			t.Error(tree.MinItem(), tree.MaxItem())
+		}
+	}
+	for i := 99; i >= 0; i-- {
+		if tree.MinItem().(Int) != Int(-i) || tree.MaxItem().(Int) != Int(i) {
+			t.Error(tree.MinItem(), tree.MaxItem())
+		}
+		tree.DeleteMin()
+		tree.DeleteAndNext(Int(i))
+	}
+	if tree.MinItem() != nil || tree.MaxItem() != nil {
+		t.Error("")
+	}
+}
+
+func TestExtremes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := New(2)
+	for i := 0; i < 5000; i++ {
+		if i%500 == 0 {
+			tree.Clone()
+		}
+		key := Int(r.Intn(1000))
+		switch r.Intn(7) {
+		case 0, 1, 2:
+			tree.Insert(key)
+		case 3:
+			tree.Delete(key)
+		case 4:
+			tree.DeleteMax()
+		case 5:
+			tree.DeleteMin()
+		case 6:
+			tree.Append(key)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatal(i, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+// Package btreebench implements a benchmark harness for the items of the B-tree.
+//
+// Run reports one sub-benchmark per degree, workload and mode, named like
+// "degree=32/search/cow", so the results of different item types can be
+// compared with benchstat.
+package btreebench
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hslam/btree"
+)
+
+// Workload represents the operation measured by a benchmark.
+type Workload int
+
+const (
+	// Insert inserts new items.
+	Insert Workload = iota
+	// Search searches the existing items.
+	Search
+	// Delete deletes the existing items and inserts them again.
+	Delete
+	// Ascend ascends the ranges of the existing items.
+	Ascend
+)
+
+// String returns the name of the workload.
+func (w Workload) String() string {
+	switch w {
+	case Insert:
+		return "insert"
+	case Search:
+		return "search"
+	case Delete:
+		return "delete"
+	case Ascend:
+		return "ascend"
+	}
+	return fmt.Sprintf("workload(%d)", int(w))
+}
+
+// Options represents the options of Run.
+type Options struct {
+	// Degrees are the degrees of the B-tree, default 2, 8, 32 and 128.
+	Degrees []int
+	// Workloads are the measured workloads, default all.
+	Workloads []Workload
+	// Size is the number of the items preloaded, default 10000.
+	Size int
+	// Span is the number of the items visited by an Ascend, default 100.
+	Span int
+	// COW also runs every workload while the B-tree is cloned every CloneEvery operations.
+	COW bool
+	// CloneEvery is the number of the operations between two clones, default 1024.
+	CloneEvery int
+}
+
+// Run benchmarks the items generated by the gen. The gen must return strictly
+// increasing items for increasing i.
+func Run(b *testing.B, gen func(i int) btree.Item, opts Options) {
+	if len(opts.Degrees) == 0 {
+		opts.Degrees = []int{2, 8, 32, 128}
+	}
+	if len(opts.Workloads) == 0 {
+		opts.Workloads = []Workload{Insert, Search, Delete, Ascend}
+	}
+	if opts.Size <= 0 {
+		opts.Size = 10000
+	}
+	if opts.Span <= 0 {
+		opts.Span = 100
+	}
+	if opts.CloneEvery <= 0 {
+		opts.CloneEvery = 1024
+	}
+	items := make([]btree.Item, opts.Size)
+	for i := range items {
+		items[i] = gen(i)
+	}
+	modes := []bool{false}
+	if opts.COW {
+		modes = append(modes, true)
+	}
+	for _, degree := range opts.Degrees {
+		for _, w := range opts.Workloads {
+			for _, cow := range modes {
+				name := fmt.Sprintf("degree=%d/%s", degree, w)
+				if cow {
+					name += "/cow"
+				}
+				degree, w, cow := degree, w, cow
+				b.Run(name, func(b *testing.B) {
+					run(b, gen, items, degree, w, cow, opts)
+				})
+			}
+		}
+	}
+}
+
+func run(b *testing.B, gen func(i int) btree.Item, items []btree.Item, degree int, w Workload, cow bool, opts Options) {
+	tree := btree.New(degree)
+	for _, item := range items {
+		tree.Insert(item)
+	}
+	var extra []btree.Item
+	if w == Insert {
+		extra = make([]btree.Item, b.N)
+		for i := range extra {
+			extra[i] = gen(len(items) + i)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if cow && i%opts.CloneEvery == 0 {
+			tree.Clone()
+		}
+		switch w {
+		case Insert:
+			tree.Insert(extra[i])
+		case Search:
+			tree.Search(items[i%len(items)])
+		case Delete:
+			item := items[i%len(items)]
+			tree.Delete(item)
+			tree.Insert(item)
+		case Ascend:
+			n := 0
+			tree.AscendGreaterOrEqual(items[i%len(items)], func(item btree.Item) bool {
+				n++
+				return n < opts.Span
+			})
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btreebench
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hslam/btree"
+)
+
+func TestWorkload(t *testing.T) {
+	if Workload(9).String() != "workload(9)" || !strings.HasPrefix(Ascend.String(), "ascend") {
+		t.Error("")
+	}
+	for _, w := range []Workload{Insert, Search, Delete, Ascend} {
+		if strings.HasPrefix(w.String(), "workload") {
+			t.Error(w)
+		}
+	}
+}
+
+func BenchmarkInt(b *testing.B) {
+	Run(b, func(i int) btree.Item { return btree.Int(i) }, Options{COW: true})
+}
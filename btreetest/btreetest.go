@@ -0,0 +1,212 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+// Package btreetest implements a conformance suite for the implementations of
+// btree.TreeInterface.
+package btreetest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hslam/btree"
+)
+
+// Item represents the item used by the suite. The items are ordered by Key,
+// and Version tells the equal items apart.
+type Item struct {
+	Key     int
+	Version int
+}
+
+// Less compares whether the current item is less than the given Item.
+func (a Item) Less(b btree.Item) bool {
+	return a.Key < b.(Item).Key
+}
+
+// Run runs the conformance suite against the trees returned by the newTree,
+// which must return a new empty tree every time it is called.
+func Run(t *testing.T, newTree func() btree.TreeInterface) {
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, newTree()) })
+	t.Run("Insert", func(t *testing.T) { testInsert(t, newTree()) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newTree()) })
+	t.Run("AscendRange", func(t *testing.T) { testAscendRange(t, newTree()) })
+	t.Run("Random", func(t *testing.T) { testRandom(t, newTree()) })
+}
+
+func testEmpty(t *testing.T, tree btree.TreeInterface) {
+	if tree.Length() != 0 || tree.Search(Item{Key: 0}) != nil {
+		t.Error("not empty")
+	}
+	if _, ok := tree.Get(Item{Key: 0}); ok {
+		t.Error("Get found an item")
+	}
+	if _, ok := tree.DeleteItem(Item{Key: 0}); ok {
+		t.Error("DeleteItem removed an item")
+	}
+	tree.Delete(Item{Key: 0})
+	tree.Ascend(func(item btree.Item) bool {
+		t.Error("Ascend visited", item)
+		return true
+	})
+	tree.Clear()
+	if tree.Length() != 0 {
+		t.Error("not empty after Clear")
+	}
+}
+
+func testInsert(t *testing.T, tree btree.TreeInterface) {
+	for i := 0; i < 100; i++ {
+		tree.Insert(Item{Key: 99 - i})
+	}
+	if tree.Length() != 100 {
+		t.Error("Length", tree.Length())
+	}
+	tree.Insert(Item{Key: 1, Version: 1})
+	if item := tree.Search(Item{Key: 1}); item == nil || item.(Item).Version != 1 {
+		t.Error("Insert did not replace", item)
+	}
+	old, replaced := tree.ReplaceOrInsert(Item{Key: 1, Version: 2})
+	if !replaced || old.(Item).Version != 1 {
+		t.Error("ReplaceOrInsert returned", old, replaced)
+	}
+	if _, replaced := tree.ReplaceOrInsert(Item{Key: 100}); replaced {
+		t.Error("ReplaceOrInsert replaced an absent item")
+	}
+	if item, ok := tree.Get(Item{Key: 1}); !ok || item.(Item).Version != 2 {
+		t.Error("Get returned", item, ok)
+	}
+	if tree.Length() != 101 {
+		t.Error("Length", tree.Length())
+	}
+	check(t, tree, keys(0, 101))
+}
+
+func testDelete(t *testing.T, tree btree.TreeInterface) {
+	for i := 0; i < 100; i++ {
+		tree.Insert(Item{Key: i, Version: i})
+	}
+	for i := 0; i < 100; i += 2 {
+		tree.Delete(Item{Key: i})
+	}
+	for i := 1; i < 50; i += 2 {
+		if removed, ok := tree.DeleteItem(Item{Key: i}); !ok || removed.(Item).Version != i {
+			t.Error("DeleteItem returned", removed, ok)
+		}
+	}
+	if _, ok := tree.DeleteItem(Item{Key: 0}); ok {
+		t.Error("DeleteItem removed a deleted item")
+	}
+	var want []int
+	for i := 51; i < 100; i += 2 {
+		want = append(want, i)
+	}
+	check(t, tree, want)
+	tree.Clear()
+	check(t, tree, nil)
+}
+
+func testAscendRange(t *testing.T, tree btree.TreeInterface) {
+	for i := 0; i < 100; i++ {
+		tree.Insert(Item{Key: i})
+	}
+	ranges := []struct {
+		lo, hi btree.Item
+		want   []int
+	}{
+		{Item{Key: 10}, Item{Key: 20}, keys(10, 20)},
+		{nil, Item{Key: 5}, keys(0, 5)},
+		{Item{Key: 95}, nil, keys(95, 100)},
+		{Item{Key: 50}, Item{Key: 50}, nil},
+		{Item{Key: 60}, Item{Key: 40}, nil},
+		{Item{Key: -10}, Item{Key: 200}, keys(0, 100)},
+	}
+	for _, r := range ranges {
+		var got []int
+		tree.AscendRange(r.lo, r.hi, func(item btree.Item) bool {
+			got = append(got, item.(Item).Key)
+			return true
+		})
+		if !equal(got, r.want) {
+			t.Error("AscendRange", r.lo, r.hi, got)
+		}
+	}
+	count := 0
+	tree.AscendRange(Item{Key: 10}, nil, func(item btree.Item) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Error("AscendRange did not stop", count)
+	}
+}
+
+func testRandom(t *testing.T, tree btree.TreeInterface) {
+	model := make(map[int]int)
+	seed := uint32(1)
+	next := func(n int) int {
+		seed = seed*1664525 + 1013904223
+		return int(seed>>8) % n
+	}
+	for i := 0; i < 5000; i++ {
+		key := next(500)
+		switch next(3) {
+		case 0, 1:
+			tree.Insert(Item{Key: key, Version: i})
+			model[key] = i
+		case 2:
+			removed, ok := tree.DeleteItem(Item{Key: key})
+			version, exists := model[key]
+			if ok != exists || ok && removed.(Item).Version != version {
+				t.Fatal("DeleteItem returned", removed, ok)
+			}
+			delete(model, key)
+		}
+	}
+	want := make([]int, 0, len(model))
+	for key := range model {
+		want = append(want, key)
+	}
+	sort.Ints(want)
+	check(t, tree, want)
+	for key, version := range model {
+		if item := tree.Search(Item{Key: key}); item == nil || item.(Item).Version != version {
+			t.Fatal("Search returned", item)
+		}
+	}
+}
+
+func check(t *testing.T, tree btree.TreeInterface, want []int) {
+	t.Helper()
+	if tree.Length() != len(want) {
+		t.Error("Length", tree.Length(), len(want))
+	}
+	var got []int
+	tree.Ascend(func(item btree.Item) bool {
+		got = append(got, item.(Item).Key)
+		return true
+	})
+	if !equal(got, want) {
+		t.Error("Ascend", got)
+	}
+}
+
+func keys(lo, hi int) []int {
+	var s []int
+	for i := lo; i < hi; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btreetest
+
+import (
+	"testing"
+
+	"github.com/hslam/btree"
+)
+
+func TestTree(t *testing.T) {
+	for _, degree := range []int{2, 3, 8} {
+		Run(t, func() btree.TreeInterface { return btree.New(degree) })
+	}
+}
+
+func TestClone(t *testing.T) {
+	Run(t, func() btree.TreeInterface {
+		tree := btree.New(2)
+		tree.Clone()
+		return tree
+	})
+}
+
+func TestSyncTree(t *testing.T) {
+	Run(t, func() btree.TreeInterface { return btree.NewSync(2) })
+}
+
+func TestShardedTree(t *testing.T) {
+	Run(t, func() btree.TreeInterface {
+		return btree.NewSharded(2, 4, func(item btree.Item) int {
+			key := item.(Item).Key
+			if key < 0 {
+				key = -key
+			}
+			return key % 4
+		})
+	})
+	Run(t, func() btree.TreeInterface {
+		bounds := []btree.Item{Item{Key: 25}, Item{Key: 50}, Item{Key: 75}}
+		return btree.NewSharded(2, 4, btree.RangeShard(bounds))
+	})
+}
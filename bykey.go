@@ -0,0 +1,111 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package btree
+
+import (
+	"cmp"
+)
+
+// KeyTree represents a B-tree of values indexed by an ordered key extracted
+// from every value, so the values can be looked up by a bare key.
+type KeyTree[T any, K cmp.Ordered] struct {
+	tree *Tree
+	key  func(v T) K
+}
+
+// keyItem caches the key of the value when it is inserted.
+type keyItem[T any, K cmp.Ordered] struct {
+	k K
+	v T
+}
+
+func (a keyItem[T, K]) Less(b Item) bool {
+	return cmp.Less(a.k, b.(keyItem[T, K]).k)
+}
+
+// NewByKey returns a new KeyTree with the given degree indexing the values by
+// the key func. The key of a value is extracted once when it is inserted.
+func NewByKey[T any, K cmp.Ordered](degree int, key func(v T) K) *KeyTree[T, K] {
+	if key == nil {
+		panic("nil key func")
+	}
+	return &KeyTree[T, K]{tree: New(degree), key: key}
+}
+
+// Len returns the number of values in the tree.
+func (t *KeyTree[T, K]) Len() int {
+	return t.tree.Length()
+}
+
+// Insert inserts the value and returns the replaced value of the same key.
+func (t *KeyTree[T, K]) Insert(v T) (old T, replaced bool) {
+	if item, ok := t.tree.ReplaceOrInsert(keyItem[T, K]{k: t.key(v), v: v}); ok {
+		return item.(keyItem[T, K]).v, true
+	}
+	return
+}
+
+// Get returns the value of the key and whether it was found.
+func (t *KeyTree[T, K]) Get(k K) (v T, ok bool) {
+	if item := t.tree.Search(keyItem[T, K]{k: k}); item != nil {
+		return item.(keyItem[T, K]).v, true
+	}
+	return
+}
+
+// Has reports whether a value of the key is in the tree.
+func (t *KeyTree[T, K]) Has(k K) bool {
+	return t.tree.Search(keyItem[T, K]{k: k}) != nil
+}
+
+// Delete deletes the value of the key and returns it.
+func (t *KeyTree[T, K]) Delete(k K) (v T, ok bool) {
+	if item, ok := t.tree.DeleteItem(keyItem[T, K]{k: k}); ok {
+		return item.(keyItem[T, K]).v, true
+	}
+	return
+}
+
+// Min returns the value of the min key, and false if the tree is empty.
+func (t *KeyTree[T, K]) Min() (v T, ok bool) {
+	if item := t.tree.MinItem(); item != nil {
+		return item.(keyItem[T, K]).v, true
+	}
+	return
+}
+
+// Max returns the value of the max key, and false if the tree is empty.
+func (t *KeyTree[T, K]) Max() (v T, ok bool) {
+	if item := t.tree.MaxItem(); item != nil {
+		return item.(keyItem[T, K]).v, true
+	}
+	return
+}
+
+// Ascend calls the fn for every value in ascending order of the keys until
+// the fn returns false.
+func (t *KeyTree[T, K]) Ascend(fn func(v T) bool) {
+	t.tree.Ascend(func(item Item) bool {
+		return fn(item.(keyItem[T, K]).v)
+	})
+}
+
+// AscendRange calls the fn for every value of the keys in the range
+// [greaterOrEqual, lessThan) in ascending order until the fn returns false.
+func (t *KeyTree[T, K]) AscendRange(greaterOrEqual, lessThan K, fn func(v T) bool) {
+	t.tree.AscendRange(keyItem[T, K]{k: greaterOrEqual}, keyItem[T, K]{k: lessThan}, func(item Item) bool {
+		return fn(item.(keyItem[T, K]).v)
+	})
+}
+
+// Descend calls the fn for every value in descending order of the keys until
+// the fn returns false.
+func (t *KeyTree[T, K]) Descend(fn func(v T) bool) {
+	t.tree.Descend(func(item Item) bool {
+		return fn(item.(keyItem[T, K]).v)
+	})
+}
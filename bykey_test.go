@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestKeyTree(t *testing.T) {
+	type user struct {
+		name string
+		age  int
+	}
+	tree := NewByKey(2, func(u *user) string { return u.name })
+	if _, ok := tree.Min(); ok {
+		t.Error("")
+	}
+	if _, ok := tree.Max(); ok {
+		t.Error("")
+	}
+	for _, name := range []string{"d", "b", "a", "c", "e"} {
+		if _, replaced := tree.Insert(&user{name: name}); replaced {
+			t.Error(name)
+		}
+	}
+	if old, replaced := tree.Insert(&user{name: "c", age: 3}); !replaced || old.age != 0 {
+		t.Error(old)
+	}
+	u, ok := tree.Get("c")
+	if !ok || u.age != 3 || tree.Len() != 5 || !tree.Has("a") || tree.Has("f") {
+		t.Error(u)
+	}
+	u.name = "z"
+	if _, ok := tree.Get("c"); !ok {
+		t.Error("")
+	}
+	if u, ok := tree.Delete("c"); !ok || u.age != 3 {
+		t.Error(u)
+	}
+	if _, ok := tree.Delete("c"); ok || tree.Len() != 4 {
+		t.Error(tree.Len())
+	}
+	if u, ok := tree.Min(); !ok || u.name != "a" {
+		t.Error(u)
+	}
+	if u, ok := tree.Max(); !ok || u.name != "e" {
+		t.Error(u)
+	}
+	var names string
+	tree.Ascend(func(u *user) bool {
+		names += u.name
+		return true
+	})
+	tree.AscendRange("b", "e", func(u *user) bool {
+		names += u.name
+		return true
+	})
+	tree.Descend(func(u *user) bool {
+		names += u.name
+		return u.name != "d"
+	})
+	if names != "abdebded" {
+		t.Error(names)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	NewByKey[int, int](2, nil)
+}
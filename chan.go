@@ -0,0 +1,62 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"context"
+)
+
+// OverflowPolicy represents the policy of Chan when the channel is full.
+type OverflowPolicy int
+
+const (
+	// Block blocks the scan until the channel has room.
+	Block OverflowPolicy = iota
+	// DropOldest drops the oldest item in the channel to make room.
+	DropOldest
+)
+
+// Chan returns a channel receiving the items from this iterator in ascending order.
+// The channel is closed after the last item or when the ctx is done. The B-tree
+// must not be mutated until the channel is closed.
+func (i *Iterator) Chan(ctx context.Context, buffer int, policy OverflowPolicy) <-chan Item {
+	if policy == DropOldest && buffer <= 0 {
+		panic("bad buffer size")
+	}
+	ch := make(chan Item, buffer)
+	iter := i.Clone()
+	go func() {
+		defer close(ch)
+		for ; iter != nil; iter = iter.Next() {
+			if !send(ctx, ch, iter.Item(), policy) {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func send(ctx context.Context, ch chan Item, item Item, policy OverflowPolicy) bool {
+	if policy == DropOldest {
+		for {
+			select {
+			case ch <- item:
+				return true
+			case <-ctx.Done():
+				return false
+			default:
+			}
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	}
+	select {
+	case ch <- item:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
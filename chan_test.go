@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIteratorChan(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	ctx := context.Background()
+	next := 0
+	for item := range tree.Min().MinIterator().Chan(ctx, 0, Block) {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+	}
+	if next != 100 {
+		t.Error(next)
+	}
+	ch := tree.Min().MinIterator().Chan(ctx, 10, DropOldest)
+	time.Sleep(time.Millisecond * 10)
+	count, last := 0, Item(nil)
+	for item := range ch {
+		count++
+		last = item
+	}
+	if count < 10 || last.(Int) != 99 {
+		t.Error(count, last)
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	ch = tree.Min().MinIterator().Chan(cctx, 0, Block)
+	<-ch
+	cancel()
+	count = 0
+	for range ch {
+		count++
+	}
+	if count > 1 {
+		t.Error(count)
+	}
+	cctx, cancel = context.WithCancel(ctx)
+	ch = tree.Min().MinIterator().Chan(cctx, 1, DropOldest)
+	cancel()
+	for range ch {
+	}
+	for range New(2).Min().MinIterator().Chan(ctx, 0, Block) {
+		t.Error("")
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	tree.Min().MinIterator().Chan(ctx, 0, DropOldest)
+}
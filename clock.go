@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock represents a source of the current time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the clock reading the system time, used by default.
+var SystemClock Clock = systemClock{}
+
+// TestClock represents a clock whose time only changes by Set and Advance,
+// so that time can be driven deterministically. It is safe for concurrent use.
+type TestClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewTestClock returns a new test clock set to the given time.
+func NewTestClock(now time.Time) *TestClock {
+	return &TestClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *TestClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set sets the current time of the clock.
+func (c *TestClock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// Advance moves the current time of the clock forward by the duration.
+func (c *TestClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// SetClock sets the clock used by the snapshots and the views of the B-tree.
+// A nil clock means the SystemClock.
+func (t *Tree) SetClock(c Clock) {
+	t.clock = c
+}
+
+func (t *Tree) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
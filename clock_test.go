@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTreeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewTestClock(start)
+	if !c.Now().Equal(start) {
+		t.Error(c.Now())
+	}
+	c.Advance(time.Hour)
+	if !c.Now().Equal(start.Add(time.Hour)) {
+		t.Error(c.Now())
+	}
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Error(c.Now())
+	}
+	if SystemClock.Now().IsZero() {
+		t.Error("")
+	}
+	tree := New(2)
+	tree.SetClock(c)
+	tree.Insert(Int(1))
+	tree.CommitSnapshot("b")
+	c.Advance(-time.Minute)
+	tree.CommitSnapshot("a")
+	infos := tree.ListSnapshots()
+	if len(infos) != 2 || infos[0].Label != "a" || !infos[1].Time.Equal(start) {
+		t.Error(infos)
+	}
+	clone := tree.Clone()
+	if !clone.now().Equal(c.Now()) {
+		t.Error(clone.now())
+	}
+	v := tree.AcquireView()
+	defer v.Close()
+	if len(tree.LeakedViews(time.Second)) != 0 {
+		t.Error("")
+	}
+	c.Advance(time.Minute)
+	if leaks := tree.LeakedViews(time.Second); len(leaks) != 1 || leaks[0].Held != time.Minute {
+		t.Error(leaks)
+	}
+	tree.SetClock(nil)
+	if tree.now().IsZero() {
+		t.Error("")
+	}
+}
+
+func TestExpiryIndexClock(t *testing.T) {
+	c := NewTestClock(time.Unix(0, 0))
+	x := NewExpiryIndex(2)
+	x.SetClock(c)
+	for i := 0; i < 10; i++ {
+		x.Set(Int(i), time.Duration(i+1)*time.Second)
+	}
+	if len(x.EvictExpired()) != 0 {
+		t.Error("")
+	}
+	c.Advance(3 * time.Second)
+	evicted := x.EvictExpired()
+	if len(evicted) != 3 || evicted[0].(Int) != 0 || evicted[2].(Int) != 2 || x.Len() != 7 {
+		t.Error(evicted)
+	}
+	c.Advance(time.Hour)
+	if len(x.EvictExpired()) != 7 || x.Len() != 0 {
+		t.Error(x.Len())
+	}
+}
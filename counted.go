@@ -0,0 +1,84 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter counts the comparisons of the counted items per item type.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[reflect.Type]*uint64
+}
+
+// NewCounter returns a new comparison counter.
+func NewCounter() *Counter {
+	return &Counter{counts: make(map[reflect.Type]*uint64)}
+}
+
+// Counted implements the Item interface counting the invocations of Less.
+type Counted struct {
+	Item  Item
+	count *uint64
+}
+
+// Less counts the comparison and returns true if a.Item is less than b.Item.
+func (a Counted) Less(b Item) bool {
+	atomic.AddUint64(a.count, 1)
+	return a.Item.Less(b.(Counted).Item)
+}
+
+// Wrap returns the counted item wrapping the item.
+func (c *Counter) Wrap(item Item) Counted {
+	typ := reflect.TypeOf(item)
+	c.mu.Lock()
+	count, ok := c.counts[typ]
+	if !ok {
+		count = new(uint64)
+		c.counts[typ] = count
+	}
+	c.mu.Unlock()
+	return Counted{Item: item, count: count}
+}
+
+// Count returns the number of the comparisons of all item types.
+func (c *Counter) Count() (total uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, count := range c.counts {
+		total += atomic.LoadUint64(count)
+	}
+	return
+}
+
+// Counts returns the number of the comparisons per item type name.
+func (c *Counter) Counts() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[string]uint64, len(c.counts))
+	for typ, count := range c.counts {
+		counts[typ.String()] += atomic.LoadUint64(count)
+	}
+	return counts
+}
+
+// Measure returns the number of the comparisons made during the fn,
+// which includes the comparisons made concurrently by other goroutines.
+func (c *Counter) Measure(fn func()) uint64 {
+	before := c.Count()
+	fn()
+	return c.Count() - before
+}
+
+// Reset resets the counts to zero.
+func (c *Counter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, count := range c.counts {
+		atomic.StoreUint64(count, 0)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestCounted(t *testing.T) {
+	c := NewCounter()
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(c.Wrap(Int(i)))
+	}
+	inserts := c.Count()
+	if inserts == 0 {
+		t.Error("")
+	}
+	n := c.Measure(func() {
+		if item := tree.Search(c.Wrap(Int(50))); item == nil || item.(Counted).Item.(Int) != 50 {
+			t.Error(item)
+		}
+	})
+	if n == 0 || n > 32 || c.Count() != inserts+n {
+		t.Error(n)
+	}
+	strings := New(2)
+	strings.Insert(c.Wrap(String("a")))
+	strings.Insert(c.Wrap(String("b")))
+	counts := c.Counts()
+	if len(counts) != 2 || counts["btree.Int"] != inserts+n || counts["btree.String"] == 0 {
+		t.Error(counts)
+	}
+	c.Reset()
+	if c.Count() != 0 {
+		t.Error(c.Count())
+	}
+}
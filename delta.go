@@ -0,0 +1,97 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrDeltas is returned when a delta stream is malformed.
+var ErrDeltas = errors.New("bad deltas")
+
+// EncodeDeltas writes the items of an Int B-tree to the w as the number of
+// items followed by the first item and the deltas between the adjacent items
+// as varints, which is compact for dense sets of integers.
+func (t *Tree) EncodeDeltas(w io.Writer) (err error) {
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(t.length))
+	if _, err = bw.Write(buf[:n]); err != nil {
+		return err
+	}
+	var last Int
+	first := true
+	t.Ascend(func(item Item) bool {
+		v, ok := item.(Int)
+		if !ok {
+			err = fmt.Errorf("%w: %T", ErrType, item)
+			return false
+		}
+		if first {
+			n = binary.PutVarint(buf[:], int64(v))
+			first = false
+		} else {
+			n = binary.PutUvarint(buf[:], uint64(v-last))
+		}
+		last = v
+		_, err = bw.Write(buf[:n])
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// DecodeDeltas returns a new B-tree with the given degree holding the Int
+// items read from the r, as written by EncodeDeltas.
+func DecodeDeltas(degree int, r io.Reader) (*Tree, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	capacity := count
+	if capacity > 1<<16 {
+		capacity = 1 << 16
+	}
+	sorted := make([]Item, 0, capacity)
+	var last Int
+	for i := uint64(0); i < count; i++ {
+		var v Int
+		if i == 0 {
+			first, err := binary.ReadVarint(br)
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			v = Int(first)
+		} else {
+			delta, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			v = last + Int(delta)
+			if v <= last {
+				return nil, ErrDeltas
+			}
+		}
+		sorted = append(sorted, v)
+		last = v
+	}
+	return LoadSorted(degree, sorted), nil
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestEncodeDeltas(t *testing.T) {
+	tree := New(3)
+	for i := -50; i < 1000; i++ {
+		if i%7 != 0 {
+			tree.Insert(Int(i))
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := tree.EncodeDeltas(buf); err != nil {
+		t.Error(err)
+	}
+	if buf.Len() > tree.Length()+8 {
+		t.Error(buf.Len())
+	}
+	decoded, err := DecodeDeltas(3, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Error(err)
+	}
+	testTraversal(decoded, t)
+	if decoded.Length() != tree.Length() {
+		t.Error(decoded.Length())
+	}
+	it := tree.Root().min().MinIterator()
+	decoded.Ascend(func(item Item) bool {
+		if item != it.Item() {
+			t.Error(item, it.Item())
+		}
+		it.Next()
+		return true
+	})
+	if decoded, err := DecodeDeltas(3, bytes.NewBuffer(buf.Bytes()[:buf.Len()/2])); err != io.ErrUnexpectedEOF || decoded != nil {
+		t.Error(err)
+	}
+	if _, err := DecodeDeltas(3, bytes.NewReader(nil)); err != io.EOF {
+		t.Error(err)
+	}
+	if _, err := DecodeDeltas(3, bytes.NewReader([]byte{2, 0, 0})); err != ErrDeltas {
+		t.Error(err)
+	}
+	empty := &bytes.Buffer{}
+	if err := New(2).EncodeDeltas(empty); err != nil || empty.Len() != 1 {
+		t.Error(err)
+	}
+	if decoded, err := DecodeDeltas(2, empty); err != nil || decoded.Length() != 0 {
+		t.Error(err)
+	}
+	strings := New(2)
+	strings.Insert(String("a"))
+	if err := strings.EncodeDeltas(&bytes.Buffer{}); !errors.Is(err, ErrType) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,405 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// DetachRange removes the items in the range [lo, hi) of the B-tree and returns
+// them as an independent tree, which the caller can drop or process later. The
+// subtrees inside the range are moved to the new tree without copying.
+// A nil bound leaves the range unbounded on that side.
+func (t *Tree) DetachRange(lo, hi Item) *Tree {
+	d := New(t.degree)
+	d.hash, d.agg, d.numeric = t.hash, t.agg, t.numeric
+	if d.root = t.removeRange(lo, hi); d.root != nil {
+		d.length = d.root.size
+		d.setExtremes()
+	}
+	return d
+}
+
+// DeleteRange removes the items in the range [greaterOrEqual, lessThan) of the
+// B-tree and returns the number of the removed items. A nil bound leaves the
+// range unbounded on that side.
+func (t *Tree) DeleteRange(greaterOrEqual, lessThan Item) int {
+	removed := t.removeRange(greaterOrEqual, lessThan)
+	removed.release()
+	return removed.Size()
+}
+
+// removeRange cuts the items in the range [lo, hi) out of the B-tree and returns
+// the root of a subtree holding them, or nil if there are none. Only the nodes
+// on the paths of the bounds are cut and rebalanced, while the subtrees inside
+// the range are moved out whole, so the removal touches O(log n) nodes. Every
+// listener of the removed items is called once.
+func (t *Tree) removeRange(lo, hi Item) *Node {
+	if t.CountRange(lo, hi) == 0 {
+		return nil
+	}
+	t.mutate()
+	var left, removed, right *Node = nil, t.root, nil
+	if lo != nil {
+		left, removed = t.cut(t.root, lo)
+	}
+	if hi != nil {
+		removed, right = t.cut(t.own(removed), hi)
+	}
+	t.root = t.own(t.concat(left, right))
+	t.length = t.root.Size()
+	t.setExtremes()
+	removed = t.own(removed)
+	if t.keys != nil || t.gens != nil {
+		removed.ascend(func(item Item) bool {
+			t.recordKey(item, nil)
+			return true
+		})
+	}
+	t.invalidateNode(removed)
+	return removed
+}
+
+// insertRange inserts the items of the subtree rooted at the n, which must be
+// in the range [lo, hi), replacing the equal items. The B-tree is cut at the
+// bounds and the subtree is joined between the parts, after the items of the
+// B-tree inside the range are merged into it one by one.
+func (t *Tree) insertRange(n *Node, lo, hi Item) {
+	if n == nil {
+		return
+	}
+	listeners := t.listenersOf(n)
+	var moved []Item
+	if t.keys != nil || t.gens != nil {
+		n.ascend(func(item Item) bool {
+			moved = append(moved, item)
+			return true
+		})
+	}
+	t.mutate()
+	var left, mid, right *Node = nil, t.root, nil
+	if mid != nil && lo != nil {
+		left, mid = t.cut(mid, lo)
+	}
+	if mid != nil && hi != nil {
+		mid, right = t.cut(t.own(mid), hi)
+	}
+	var replaced []Item
+	if mid != nil {
+		merged := &Tree{degree: t.degree}
+		if mid.Size() <= n.Size() {
+			merged.root = t.own(n)
+			merged.setExtremes()
+			mid.ascend(func(item Item) bool {
+				if merged.insert(item, false) != nil {
+					replaced = append(replaced, item)
+				}
+				return true
+			})
+			mid.release()
+		} else {
+			merged.root = t.own(mid)
+			merged.setExtremes()
+			n.ascend(func(item Item) bool {
+				if old := merged.insert(item, true); old != nil {
+					replaced = append(replaced, old)
+				}
+				return true
+			})
+			n.release()
+		}
+		mid = merged.root
+	} else {
+		mid = n
+	}
+	t.root = t.own(t.concat(t.concat(left, mid), right))
+	t.length = t.root.Size()
+	t.setExtremes()
+	for _, old := range replaced {
+		t.recordKey(old, nil)
+	}
+	for _, item := range moved {
+		t.recordKey(nil, item)
+	}
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
+// own returns the n, or a copy of it if it is shared, to be mutated in place as
+// the root of a subtree cut out of the B-tree.
+func (t *Tree) own(n *Node) *Node {
+	if n == nil {
+		return nil
+	}
+	if n.shared() {
+		c := n.clone(nil)
+		n.release()
+		return c
+	}
+	n.parent = nil
+	atomic.StoreInt32(&n.orphan, 0)
+	return n
+}
+
+// cut splits the subtree rooted at the n, which must be mutable, into the
+// subtrees of the items less than the key and of the items not less than it,
+// and returns their roots, nil for an empty one. The nodes on the path of the
+// key are cut and the pieces on either side are joined back, while the other
+// subtrees move to either side whole.
+func (t *Tree) cut(n *Node, key Item) (left, right *Node) {
+	i, found := n.items.search(key)
+	if len(n.children) == 0 {
+		r := newNode(t.MaxItems())
+		r.items = append(r.items, n.items[i:]...)
+		n.items.truncate(i)
+		return t.fragment(n), t.fragment(r)
+	}
+	var childLeft, childRight *Node
+	if found {
+		childLeft = n.children[i]
+	} else {
+		childLeft, childRight = t.cut(n.mutableChild(i), key)
+	}
+	count := len(n.items)
+	r := newNode(t.MaxItems())
+	var leftSep, rightSep Item
+	if i < count {
+		rightSep = n.items[i]
+		r.items = append(r.items, n.items[i+1:]...)
+		r.children = append(r.children, n.children[i+1:]...)
+		for _, child := range r.children {
+			r.adopt(child)
+		}
+	}
+	if i > 0 {
+		leftSep = n.items[i-1]
+		n.items.truncate(i - 1)
+	} else {
+		n.items.truncate(0)
+	}
+	n.children.truncate(i)
+	left, right = childLeft, childRight
+	if i > 0 {
+		left = t.join(t.fragment(n), leftSep, childLeft)
+	}
+	if i < count {
+		right = t.join(childRight, rightSep, t.fragment(r))
+	}
+	return
+}
+
+// fragment returns the n as the root of a subtree, or its only child if it
+// holds no items, or nil if it is empty.
+func (t *Tree) fragment(n *Node) *Node {
+	if len(n.items) > 0 {
+		n.update()
+		return n
+	}
+	if len(n.children) == 0 {
+		return nil
+	}
+	return n.children[0]
+}
+
+// concat joins the subtrees l and r, whose items are in ascending order, with
+// the max item of l as the separator.
+func (t *Tree) concat(l, r *Node) *Node {
+	if l == nil || r == nil {
+		if l == nil {
+			return r
+		}
+		return l
+	}
+	rest := &Tree{degree: t.degree, root: t.own(l)}
+	rest.setExtremes()
+	sep := rest.delete(nil, 0, removeMax)
+	return t.join(rest.root, sep, r)
+}
+
+// join returns the root of the subtree holding the items of the subtree l, the
+// sep and the items of the subtree r, which must be in ascending order. The
+// lower subtree is grafted onto the spine of the higher one, so only the nodes
+// on the spine are touched.
+func (t *Tree) join(l *Node, sep Item, r *Node) *Node {
+	if l == nil && r == nil {
+		n := newNode(t.MaxItems())
+		n.items = append(n.items, sep)
+		n.update()
+		return n
+	}
+	hl, hr := l.Depth(), r.Depth()
+	if hl == hr {
+		l, r = t.own(l), t.own(r)
+		sep, r = t.balance(l, sep, r)
+		if r == nil {
+			return l
+		}
+		root := newNode(t.MaxItems())
+		root.items = append(root.items, sep)
+		root.children = append(root.children, l, r)
+		root.adopt(l)
+		root.adopt(r)
+		root.update()
+		return root
+	}
+	if hl > hr {
+		return t.graft(t.own(l), hl-hr, sep, r, true)
+	}
+	return t.graft(t.own(r), hr-hl, sep, l, false)
+}
+
+// graft adds the sep and the subtree sub, which is lower by the given number of
+// levels, to the right end of the subtree rooted at the n if right is true, or
+// else to the left end, and returns the new root. The nodes on the spine are
+// split on overflow as on insertion.
+func (t *Tree) graft(n *Node, levels int, sep Item, sub *Node, right bool) *Node {
+	path := []*Node{n}
+	for ; levels > 1; levels-- {
+		if right {
+			n = n.mutableChild(len(n.children) - 1)
+		} else {
+			n = n.mutableChild(0)
+		}
+		path = append(path, n)
+	}
+	switch {
+	case sub == nil && right:
+		n.items = append(n.items, sep)
+	case sub == nil:
+		n.items.insert(0, sep)
+	case right:
+		if len(sub.items) < t.MinItems() {
+			sep, sub = t.balance(n.mutableChild(len(n.children)-1), sep, t.own(sub))
+		}
+		if sub != nil {
+			n.items = append(n.items, sep)
+			n.children = append(n.children, sub)
+			n.adopt(sub)
+		}
+	default:
+		sibling := n.children[0]
+		if len(sub.items) < t.MinItems() {
+			sub = t.own(sub)
+			if sep, sibling = t.balance(sub, sep, n.mutableChild(0)); sibling == nil {
+				n.children[0] = sub
+				n.adopt(sub)
+				break
+			}
+		}
+		n.items.insert(0, sep)
+		n.children.insert(0, sub)
+		n.adopt(sub)
+	}
+	for d := len(path) - 1; d >= 0; d-- {
+		n := path[d]
+		if len(n.items) <= t.MaxItems() {
+			n.update()
+			continue
+		}
+		median, split := t.splitOver(n)
+		if d == 0 {
+			root := newNode(t.MaxItems())
+			root.items = append(root.items, median)
+			root.children = append(root.children, n, split)
+			root.adopt(n)
+			root.adopt(split)
+			root.update()
+			return root
+		}
+		if p := path[d-1]; right {
+			p.items = append(p.items, median)
+			p.children = append(p.children, split)
+			p.adopt(split)
+		} else {
+			p.items.insert(0, median)
+			p.children.insert(1, split)
+			p.adopt(split)
+		}
+	}
+	return path[0]
+}
+
+// balance joins the mutable siblings a and b of the same height with the sep
+// between them into a if they fit in a node, and returns a nil b. Otherwise it
+// moves the items so that both hold at least the min number of items, and
+// returns the new separator with b.
+func (t *Tree) balance(a *Node, sep Item, b *Node) (Item, *Node) {
+	if len(a.items)+1+len(b.items) <= t.MaxItems() {
+		a.items = append(a.items, sep)
+		a.items = append(a.items, b.items...)
+		a.children = append(a.children, b.children...)
+		for _, child := range b.children {
+			a.adopt(child)
+		}
+		a.update()
+		return nil, nil
+	}
+	if len(a.items) < t.MinItems() || len(b.items) < t.MinItems() {
+		items := make([]Item, 0, len(a.items)+1+len(b.items))
+		items = append(append(append(items, a.items...), sep), b.items...)
+		children := append(append([]*Node(nil), a.children...), b.children...)
+		mid := len(items) / 2
+		a.items.truncate(0)
+		a.items = append(a.items, items[:mid]...)
+		b.items.truncate(0)
+		b.items = append(b.items, items[mid+1:]...)
+		sep = items[mid]
+		if len(children) > 0 {
+			a.children.truncate(0)
+			a.children = append(a.children, children[:mid+1]...)
+			b.children.truncate(0)
+			b.children = append(b.children, children[mid+1:]...)
+			for _, child := range a.children {
+				a.adopt(child)
+			}
+			for _, child := range b.children {
+				b.adopt(child)
+			}
+		}
+		a.update()
+		b.update()
+	}
+	return sep, b
+}
+
+// splitOver splits the node holding one item more than the max around the
+// median item, keeping the capacities of the nodes.
+func (t *Tree) splitOver(n *Node) (median Item, right *Node) {
+	i := len(n.items) / 2
+	median = n.items[i]
+	right = newNode(t.MaxItems())
+	right.items = append(right.items, n.items[i+1:]...)
+	items := make([]Item, i, t.MaxItems())
+	copy(items, n.items)
+	n.items = items
+	if len(n.children) > 0 {
+		right.children = append(right.children, n.children[i+1:]...)
+		children := make([]*Node, i+1, t.MaxItems()+1)
+		copy(children, n.children)
+		n.children = children
+		for _, child := range right.children {
+			right.adopt(child)
+		}
+	}
+	n.update()
+	right.update()
+	return
+}
+
+// invalidateItems calls once every listener whose range holds any of the sorted items.
+func (t *Tree) invalidateItems(sorted []Item) {
+	if t.listeners == nil {
+		return
+	}
+	for _, l := range t.listeners.list {
+		i := sort.Search(len(sorted), func(i int) bool {
+			return !sorted[i].Less(l.lo)
+		})
+		if i < len(sorted) && sorted[i].Less(l.hi) {
+			l.fn()
+		}
+	}
+}
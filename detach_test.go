@@ -0,0 +1,206 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDetachRange(t *testing.T) {
+	for _, r := range []Range{{Lo: Int(10), Hi: Int(15)}, {Lo: Int(10), Hi: Int(90)}, {Hi: Int(50)}, {Lo: Int(50)}, {}, {Lo: Int(200)}} {
+		tree := New(2)
+		for i := 0; i < 100; i++ {
+			tree.Insert(Int(i))
+		}
+		clone := tree.Clone()
+		var inside, outside int
+		tree.OnRangeInvalidate(Int(0), Int(100), func() { inside++ })
+		tree.OnRangeInvalidate(Int(100), Int(200), func() { outside++ })
+		want := 0
+		tree.AscendRange(r.Lo, r.Hi, func(item Item) bool {
+			want++
+			return true
+		})
+		d := tree.DetachRange(r.Lo, r.Hi)
+		testShared(tree, t)
+		testShared(d, t)
+		if err := d.Validate(); err != nil {
+			t.Error(r, err)
+		}
+		testShared(clone, t)
+		if d.Length() != want || tree.Length() != 100-want || clone.Length() != 100 {
+			t.Error(r, d.Length(), tree.Length())
+		}
+		if want > 0 && inside != 1 || want == 0 && inside != 0 || outside != 0 {
+			t.Error(r, inside, outside)
+		}
+		d.Ascend(func(item Item) bool {
+			if r.Lo != nil && item.Less(r.Lo) || r.Hi != nil && !item.Less(r.Hi) || tree.Search(item) != nil {
+				t.Error(item)
+			}
+			return true
+		})
+		tree.Insert(Int(10))
+		testShared(tree, t)
+	}
+	if d := New(2).DetachRange(nil, nil); d.Length() != 0 {
+		t.Error(d.Length())
+	}
+}
+
+func TestDetachRangeMove(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 100000; i++ {
+		tree.Insert(Int(i))
+	}
+	old := map[*Node]bool{}
+	testWalk(tree.Root(), func(n *Node) { old[n] = true })
+	d := tree.DetachRange(Int(1234), Int(98765))
+	if err := d.Validate(); err != nil || d.Length() != 98765-1234 {
+		t.Fatal(err, d.Length())
+	}
+	if err := tree.Validate(); err != nil || tree.Length() != 100000-d.Length() {
+		t.Fatal(err, tree.Length())
+	}
+	testCapacity(d.Root(), d.MaxItems(), t)
+	created, height := 0, d.Root().Depth()
+	testWalk(d.Root(), func(n *Node) {
+		if !old[n] {
+			created++
+		}
+	})
+	if created > 4*height {
+		t.Error(created, height)
+	}
+	for i := 1234; i < 98765; i++ {
+		if d.Search(Int(i)) == nil || tree.Search(Int(i)) != nil {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(Int(i))
+	}
+	if n := tree.DeleteRange(Int(100), Int(900)); n != 800 || tree.Length() != 200 {
+		t.Error(n, tree.Length())
+	}
+	testTraversal(tree, t)
+	if n := tree.DeleteRange(Int(0), Int(10)); n != 10 || tree.Length() != 190 {
+		t.Error(n, tree.Length())
+	}
+	testTraversal(tree, t)
+	if n := tree.DeleteRange(Int(100), Int(900)); n != 0 {
+		t.Error(n)
+	}
+	if n := tree.DeleteRange(nil, nil); n != 190 || tree.Length() != 0 || tree.Root() != nil {
+		t.Error(n, tree.Length())
+	}
+	testTraversal(tree, t)
+}
+
+func TestDeleteRangeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, degree := range []int{2, 3, 5} {
+		for round := 0; round < 200; round++ {
+			tree := New(degree)
+			model := map[int]bool{}
+			for i, n := 0, r.Intn(500); i < n; i++ {
+				k := r.Intn(1000)
+				tree.Insert(Int(k))
+				model[k] = true
+			}
+			clone := tree.Clone()
+			length := tree.Length()
+			for j := 0; j < 3; j++ {
+				var lo, hi Item
+				a, b := r.Intn(1100)-50, r.Intn(1100)-50
+				if a > b {
+					a, b = b, a
+				}
+				if r.Intn(5) > 0 {
+					lo = Int(a)
+				}
+				if r.Intn(5) > 0 {
+					hi = Int(b)
+				}
+				before, count := tree.Clone(), tree.Length()
+				want := 0
+				for k := range model {
+					if (lo == nil || k >= a) && (hi == nil || k < b) {
+						delete(model, k)
+						want++
+					}
+				}
+				if n := tree.DeleteRange(lo, hi); n != want || tree.Length() != len(model) {
+					t.Error(degree, round, lo, hi, n, want)
+				}
+				if err := tree.Validate(); err != nil {
+					t.Fatal(degree, round, err)
+				}
+				testCapacity(tree.Root(), tree.MaxItems(), t)
+				if err := before.Validate(); err != nil || before.Length() != count {
+					t.Fatal(degree, round, err)
+				}
+				tree.Ascend(func(item Item) bool {
+					if !model[int(item.(Int))] {
+						t.Error(item)
+					}
+					return true
+				})
+			}
+			if err := clone.Validate(); err != nil || clone.Length() != length {
+				t.Fatal(degree, round, err)
+			}
+		}
+	}
+}
+
+func TestDeleteRangeTouched(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 100000; i++ {
+		tree.Insert(Int(i))
+	}
+	old := map[*Node]bool{}
+	testWalk(tree.Root(), func(n *Node) { old[n] = true })
+	height := tree.Root().Depth()
+	tree.DeleteRange(Int(1234), Int(98765))
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	created := 0
+	testWalk(tree.Root(), func(n *Node) {
+		if !old[n] {
+			created++
+		}
+	})
+	if created > 4*height {
+		t.Error(created, height)
+	}
+}
+
+func testCapacity(n *Node, max int, t *testing.T) {
+	if n == nil {
+		return
+	}
+	if cap(n.items) != max || len(n.children) > 0 && cap(n.children) != max+1 {
+		t.Error(cap(n.items), cap(n.children))
+	}
+	for _, child := range n.children {
+		testCapacity(child, max, t)
+	}
+}
+
+func testWalk(n *Node, fn func(n *Node)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	for _, child := range n.children {
+		testWalk(child, fn)
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+// Package disk implements a disk-backed B+tree storing its nodes as fixed-size
+// pages of a file, for trees larger than the memory.
+//
+// The keys and the values are byte slices ordered by bytes.Compare. The values
+// are held by the leaves, and a lookup reads one page per level. The pages are
+// allocated from a free list of the pages released by the deletions, and a page
+// is released when it becomes empty; partially filled pages are not merged.
+//
+// The pages are written in place without a journal, so a crash during a write
+// may corrupt the file. A Tree is not safe for concurrent use.
+//
+// An ItemTree stores the items of the btree package in a Tree, implementing
+// the btree.TreeInterface.
+package disk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+const (
+	// MinPageSize is the min size of a page.
+	MinPageSize = 128
+	// MaxPageSize is the max size of a page.
+	MaxPageSize = 1 << 16
+)
+
+var (
+	// ErrPageSize is returned when the page size is out of [MinPageSize, MaxPageSize].
+	ErrPageSize = errors.New("bad page size")
+	// ErrCorrupt is returned when a page of the file is malformed.
+	ErrCorrupt = errors.New("corrupt page")
+	// ErrTooLarge is returned when the key and the value are too large for a page.
+	ErrTooLarge = errors.New("entry too large")
+	// ErrEmptyKey is returned when the key is empty.
+	ErrEmptyKey = errors.New("empty key")
+)
+
+var magic = [4]byte{'B', 'T', 'R', 'D'}
+
+const version = 1
+
+// The header page holds the magic, the version, the page size, the root page,
+// the head of the free list, the number of pages and the number of keys.
+const headerSize = 4 + 1 + 4 + 4 + 4 + 4 + 8
+
+// Tree represents a disk-backed B+tree.
+type Tree struct {
+	file     *os.File
+	pageSize int
+	root     uint32
+	free     uint32
+	pages    uint32
+	length   uint64
+}
+
+// Open opens the B+tree in the file at the path, creating the file if it does
+// not exist. The page size is used when the file is created; an existing file
+// keeps its page size.
+func Open(path string, pageSize int) (*Tree, error) {
+	if pageSize < MinPageSize || pageSize > MaxPageSize {
+		return nil, ErrPageSize
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tree{file: file, pageSize: pageSize}
+	info, err := file.Stat()
+	if err == nil {
+		if info.Size() == 0 {
+			err = t.create()
+		} else {
+			err = t.readHeader()
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Tree) create() error {
+	t.root, t.pages = 1, 2
+	if err := t.write(&node{id: t.root, leaf: true}); err != nil {
+		return err
+	}
+	return t.writeHeader()
+}
+
+func (t *Tree) readHeader() error {
+	var header [headerSize]byte
+	if _, err := t.file.ReadAt(header[:], 0); err != nil {
+		return ErrCorrupt
+	}
+	if !bytes.Equal(header[:4], magic[:]) || header[4] != version {
+		return ErrCorrupt
+	}
+	t.pageSize = int(binary.LittleEndian.Uint32(header[5:]))
+	t.root = binary.LittleEndian.Uint32(header[9:])
+	t.free = binary.LittleEndian.Uint32(header[13:])
+	t.pages = binary.LittleEndian.Uint32(header[17:])
+	t.length = binary.LittleEndian.Uint64(header[21:])
+	if t.pageSize < MinPageSize || t.pageSize > MaxPageSize || t.root == 0 || t.root >= t.pages {
+		return ErrCorrupt
+	}
+	return nil
+}
+
+func (t *Tree) writeHeader() error {
+	var header [headerSize]byte
+	copy(header[:], magic[:])
+	header[4] = version
+	binary.LittleEndian.PutUint32(header[5:], uint32(t.pageSize))
+	binary.LittleEndian.PutUint32(header[9:], t.root)
+	binary.LittleEndian.PutUint32(header[13:], t.free)
+	binary.LittleEndian.PutUint32(header[17:], t.pages)
+	binary.LittleEndian.PutUint64(header[21:], t.length)
+	_, err := t.file.WriteAt(header[:], 0)
+	return err
+}
+
+// Close writes the header and closes the file.
+func (t *Tree) Close() error {
+	err := t.writeHeader()
+	if e := t.file.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Sync commits the written pages to the disk.
+func (t *Tree) Sync() error {
+	return t.file.Sync()
+}
+
+// Len returns the number of keys.
+func (t *Tree) Len() int {
+	return int(t.length)
+}
+
+// Pages returns the number of pages of the file, including the header page
+// and the free pages.
+func (t *Tree) Pages() int {
+	return int(t.pages)
+}
+
+// PageSize returns the size of a page.
+func (t *Tree) PageSize() int {
+	return t.pageSize
+}
+
+// maxEntry returns the max size of a key with its value, which keeps both
+// halves of a split page within the page size.
+func (t *Tree) maxEntry() int {
+	return (t.pageSize - nodeHeaderSize - 4) / 4
+}
+
+func (t *Tree) read(id uint32) (*node, error) {
+	if id == 0 || id >= t.pages {
+		return nil, ErrCorrupt
+	}
+	page := make([]byte, t.pageSize)
+	if _, err := t.file.ReadAt(page, int64(id)*int64(t.pageSize)); err != nil {
+		return nil, err
+	}
+	return decode(id, page)
+}
+
+func (t *Tree) write(n *node) error {
+	if n.size() > t.pageSize {
+		panic("page overflow")
+	}
+	page := make([]byte, t.pageSize)
+	n.encode(page)
+	_, err := t.file.WriteAt(page, int64(n.id)*int64(t.pageSize))
+	return err
+}
+
+// alloc returns a page from the free list, or appends a new page to the file.
+func (t *Tree) alloc() (uint32, error) {
+	if t.free == 0 {
+		t.pages++
+		return t.pages - 1, nil
+	}
+	id := t.free
+	var next [5]byte
+	if _, err := t.file.ReadAt(next[:], int64(id)*int64(t.pageSize)); err != nil {
+		return 0, err
+	}
+	if next[0] != freePage {
+		return 0, ErrCorrupt
+	}
+	t.free = binary.LittleEndian.Uint32(next[1:])
+	return id, nil
+}
+
+// release pushes the page onto the free list.
+func (t *Tree) release(id uint32) error {
+	var next [5]byte
+	next[0] = freePage
+	binary.LittleEndian.PutUint32(next[1:], t.free)
+	if _, err := t.file.WriteAt(next[:], int64(id)*int64(t.pageSize)); err != nil {
+		return err
+	}
+	t.free = id
+	return nil
+}
+
+// Get returns the value of the key and whether it was found.
+func (t *Tree) Get(key []byte) (value []byte, ok bool, err error) {
+	n, err := t.read(t.root)
+	for err == nil && !n.leaf {
+		n, err = t.read(n.children[n.child(key)])
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if i, found := n.search(key); found {
+		return n.values[i], true, nil
+	}
+	return nil, false, nil
+}
+
+// Put sets the value of the key.
+func (t *Tree) Put(key, value []byte) error {
+	if len(key) == 0 {
+		return ErrEmptyKey
+	}
+	if 4+len(key)+len(value) > t.maxEntry() {
+		return ErrTooLarge
+	}
+	promoted, right, err := t.put(t.root, key, value)
+	if err != nil {
+		return err
+	}
+	if right != 0 {
+		id, err := t.alloc()
+		if err != nil {
+			return err
+		}
+		root := &node{id: id, keys: [][]byte{promoted}, children: []uint32{t.root, right}}
+		if err := t.write(root); err != nil {
+			return err
+		}
+		t.root = id
+	}
+	return t.writeHeader()
+}
+
+// put sets the value of the key in the subtree of the page, and returns the
+// key and the page of the right half if the page was split.
+func (t *Tree) put(id uint32, key, value []byte) (promoted []byte, right uint32, err error) {
+	n, err := t.read(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n.leaf {
+		i, found := n.search(key)
+		if found {
+			n.values[i] = value
+		} else {
+			n.keys = insert(n.keys, i, key)
+			n.values = insert(n.values, i, value)
+			t.length++
+		}
+	} else {
+		i := n.child(key)
+		promoted, right, err = t.put(n.children[i], key, value)
+		if err != nil || right == 0 {
+			return nil, 0, err
+		}
+		n.keys = insert(n.keys, i, promoted)
+		n.children = append(n.children, 0)
+		copy(n.children[i+2:], n.children[i+1:])
+		n.children[i+1] = right
+	}
+	if n.size() <= t.pageSize {
+		return nil, 0, t.write(n)
+	}
+	return t.split(n)
+}
+
+// split moves the right half of the overflowing node to a new page.
+func (t *Tree) split(n *node) (promoted []byte, right uint32, err error) {
+	if right, err = t.alloc(); err != nil {
+		return nil, 0, err
+	}
+	mid := n.splitIndex()
+	r := &node{id: right, leaf: n.leaf}
+	if n.leaf {
+		promoted = n.keys[mid]
+		r.keys = append(r.keys, n.keys[mid:]...)
+		r.values = append(r.values, n.values[mid:]...)
+		n.keys, n.values = n.keys[:mid], n.values[:mid]
+	} else {
+		promoted = n.keys[mid]
+		r.keys = append(r.keys, n.keys[mid+1:]...)
+		r.children = append(r.children, n.children[mid+1:]...)
+		n.keys, n.children = n.keys[:mid], n.children[:mid+1]
+	}
+	if err = t.write(r); err != nil {
+		return nil, 0, err
+	}
+	return promoted, right, t.write(n)
+}
+
+func insert(s [][]byte, i int, b []byte) [][]byte {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = b
+	return s
+}
+
+// Delete deletes the key and reports whether it existed.
+func (t *Tree) Delete(key []byte) (bool, error) {
+	found, _, err := t.delete(t.root, key)
+	if err != nil || !found {
+		return found, err
+	}
+	for {
+		n, err := t.read(t.root)
+		if err != nil {
+			return true, err
+		}
+		if n.leaf || len(n.keys) > 0 {
+			break
+		}
+		if err := t.release(n.id); err != nil {
+			return true, err
+		}
+		t.root = n.children[0]
+	}
+	return true, t.writeHeader()
+}
+
+// delete deletes the key from the subtree of the page, and reports whether the
+// page became empty and was released.
+func (t *Tree) delete(id uint32, key []byte) (found, empty bool, err error) {
+	n, err := t.read(id)
+	if err != nil {
+		return false, false, err
+	}
+	if n.leaf {
+		i, ok := n.search(key)
+		if !ok {
+			return false, false, nil
+		}
+		copy(n.keys[i:], n.keys[i+1:])
+		copy(n.values[i:], n.values[i+1:])
+		n.keys, n.values = n.keys[:len(n.keys)-1], n.values[:len(n.values)-1]
+		t.length--
+	} else {
+		i := n.child(key)
+		if found, empty, err = t.delete(n.children[i], key); err != nil || !empty {
+			return found, false, err
+		}
+		copy(n.children[i:], n.children[i+1:])
+		n.children = n.children[:len(n.children)-1]
+		if len(n.keys) > 0 {
+			k := i
+			if k > 0 {
+				k--
+			}
+			copy(n.keys[k:], n.keys[k+1:])
+			n.keys = n.keys[:len(n.keys)-1]
+		}
+	}
+	if id != t.root && (n.leaf && len(n.keys) == 0 || !n.leaf && len(n.children) == 0) {
+		return true, true, t.release(id)
+	}
+	return true, false, t.write(n)
+}
+
+// Ascend calls the fn for every key and value in ascending order of the keys
+// until the fn returns false.
+func (t *Tree) Ascend(fn func(key, value []byte) bool) error {
+	return t.AscendRange(nil, nil, fn)
+}
+
+// AscendRange calls the fn for every key in the range [greaterOrEqual, lessThan)
+// and its value in ascending order until the fn returns false. A nil bound
+// leaves the range unbounded on that side.
+func (t *Tree) AscendRange(greaterOrEqual, lessThan []byte, fn func(key, value []byte) bool) error {
+	_, err := t.ascend(t.root, greaterOrEqual, lessThan, fn)
+	return err
+}
+
+func (t *Tree) ascend(id uint32, lo, hi []byte, fn func(key, value []byte) bool) (bool, error) {
+	n, err := t.read(id)
+	if err != nil {
+		return false, err
+	}
+	if n.leaf {
+		i := 0
+		if lo != nil {
+			i, _ = n.search(lo)
+		}
+		for ; i < len(n.keys); i++ {
+			if hi != nil && bytes.Compare(n.keys[i], hi) >= 0 || !fn(n.keys[i], n.values[i]) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	i := 0
+	if lo != nil {
+		i = n.child(lo)
+	}
+	for ; i < len(n.children); i++ {
+		if i > 0 && hi != nil && bytes.Compare(n.keys[i-1], hi) >= 0 {
+			return false, nil
+		}
+		if ok, err := t.ascend(n.children[i], lo, hi, fn); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package disk
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func key(i int) []byte {
+	return []byte(fmt.Sprintf("key-%05d", i))
+}
+
+func value(i int) []byte {
+	return bytes.Repeat([]byte{byte(i)}, i%13)
+}
+
+func count(t *testing.T, tree *Tree, lo, hi []byte) (n int) {
+	var last []byte
+	err := tree.AscendRange(lo, hi, func(k, v []byte) bool {
+		if last != nil && bytes.Compare(last, k) >= 0 {
+			t.Error(string(last), string(k))
+		}
+		last = append(last[:0], k...)
+		n++
+		return true
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	return
+}
+
+func TestTree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree")
+	if _, err := Open(path, MinPageSize-1); err != ErrPageSize {
+		t.Error(err)
+	}
+	tree, err := Open(path, MinPageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 0 || count(t, tree, nil, nil) != 0 {
+		t.Error(tree.Len())
+	}
+	if tree.Put(nil, nil) != ErrEmptyKey || tree.Put(key(0), make([]byte, MinPageSize)) != ErrTooLarge {
+		t.Error("")
+	}
+	const n = 2000
+	for i := 0; i < n; i++ {
+		if err := tree.Put(key(i*7%n), value(i*7%n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tree.Put(key(5), []byte("five")); err != nil {
+		t.Error(err)
+	}
+	if tree.Len() != n || count(t, tree, nil, nil) != n || count(t, tree, key(100), key(200)) != 100 {
+		t.Error(tree.Len())
+	}
+	if count(t, tree, key(n-1), nil) != 1 || count(t, tree, nil, key(0)) != 0 {
+		t.Error("")
+	}
+	stopped := 0
+	tree.Ascend(func(k, v []byte) bool {
+		stopped++
+		return stopped < 10
+	})
+	if stopped != 10 {
+		t.Error(stopped)
+	}
+	if err := tree.Sync(); err != nil {
+		t.Error(err)
+	}
+	if err := tree.Close(); err != nil {
+		t.Error(err)
+	}
+	tree, err = Open(path, MaxPageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	if tree.PageSize() != MinPageSize || tree.Len() != n {
+		t.Error(tree.PageSize(), tree.Len())
+	}
+	for i := 0; i < n; i++ {
+		v, ok, err := tree.Get(key(i))
+		if i == 5 {
+			if string(v) != "five" {
+				t.Error(string(v))
+			}
+		} else if !ok || err != nil || !bytes.Equal(v, value(i)) {
+			t.Error(i, ok, err)
+		}
+	}
+	if _, ok, err := tree.Get(key(n)); ok || err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < n; i += 2 {
+		if ok, err := tree.Delete(key(i)); !ok || err != nil {
+			t.Error(i, err)
+		}
+	}
+	if ok, err := tree.Delete(key(0)); ok || err != nil || tree.Len() != n/2 {
+		t.Error(tree.Len())
+	}
+	if count(t, tree, nil, nil) != n/2 {
+		t.Error("")
+	}
+	for i := n / 2; i < n; i++ {
+		tree.Delete(key(i))
+	}
+	if count(t, tree, nil, nil) != n/4 || tree.free == 0 {
+		t.Error(tree.Len())
+	}
+	for i := n / 2; i < n; i++ {
+		if err := tree.Put(key(i), value(i)); err != nil {
+			t.Error(err)
+		}
+	}
+	if count(t, tree, nil, nil) != n/4+n/2 {
+		t.Error(tree.Len())
+	}
+	for i := 0; i < n; i++ {
+		tree.Delete(key(i))
+	}
+	if tree.Len() != 0 || count(t, tree, nil, nil) != 0 {
+		t.Error(tree.Len())
+	}
+	pages := tree.Pages()
+	for i := 0; i < n/4; i++ {
+		if err := tree.Put(key(i), value(i)); err != nil {
+			t.Error(err)
+		}
+	}
+	if tree.Pages() != pages || count(t, tree, nil, nil) != n/4 {
+		t.Error(tree.Pages(), pages)
+	}
+	for i := 0; i < n/4; i++ {
+		tree.Delete(key(i))
+	}
+	if root, _ := tree.read(tree.root); !root.leaf {
+		t.Error("")
+	}
+	if err := tree.Put(key(1), value(1)); err != nil || tree.Len() != 1 {
+		t.Error(err)
+	}
+}
+
+func TestLargeEntries(t *testing.T) {
+	tree, err := Open(filepath.Join(t.TempDir(), "tree"), MinPageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	max := tree.maxEntry() - 4
+	for i := 0; i < 1000; i++ {
+		k := []byte(fmt.Sprintf("%0*d", 1+i*7%max, i))
+		v := make([]byte, max-len(k))
+		if err := tree.Put(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tree.Len() != 1000 || count(t, tree, nil, nil) != 1000 {
+		t.Error(tree.Len())
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree")
+	if err := ioutil.WriteFile(path, []byte("not a tree"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, MinPageSize); err != ErrCorrupt {
+		t.Error(err)
+	}
+	if _, err := decode(1, []byte{leafPage, 1, 0, 5, 0, 0, 0, 'a'}); err != ErrCorrupt {
+		t.Error(err)
+	}
+	if _, err := decode(1, []byte{internalPage, 1, 0, 1, 0, 0, 0}); err != ErrCorrupt {
+		t.Error(err)
+	}
+	if _, err := decode(1, []byte{freePage}); err != ErrCorrupt {
+		t.Error(err)
+	}
+}
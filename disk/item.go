@@ -0,0 +1,142 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package disk
+
+import (
+	"github.com/hslam/btree"
+)
+
+// ItemTree adapts a Tree to the btree.TreeInterface. Every item is stored with
+// the key encoded by the key func as the key and the item encoded by the codec
+// as the value, so the key func must encode the items into non-empty keys
+// ordered by bytes.Compare as the items are ordered by Less.
+//
+// Since the interface has no error results, the methods panic on the errors
+// of the file and the codec.
+type ItemTree struct {
+	tree  *Tree
+	key   func(item btree.Item) []byte
+	codec btree.Codec
+}
+
+var _ btree.TreeInterface = (*ItemTree)(nil)
+
+// NewItemTree returns a new ItemTree storing the items in the tree.
+func NewItemTree(tree *Tree, key func(item btree.Item) []byte, codec btree.Codec) *ItemTree {
+	if tree == nil || key == nil || codec == nil {
+		panic("nil tree, key func or codec")
+	}
+	return &ItemTree{tree: tree, key: key, codec: codec}
+}
+
+// Tree returns the underlying Tree.
+func (t *ItemTree) Tree() *Tree {
+	return t.tree
+}
+
+// Length returns the number of items.
+func (t *ItemTree) Length() int {
+	return t.tree.Len()
+}
+
+// Search returns the item equal to the item, or nil if there is none.
+func (t *ItemTree) Search(item btree.Item) btree.Item {
+	found, _ := t.Get(item)
+	return found
+}
+
+// Get returns the item equal to the item and whether it was found.
+func (t *ItemTree) Get(item btree.Item) (btree.Item, bool) {
+	value, ok, err := t.tree.Get(t.key(item))
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		return nil, false
+	}
+	return t.decode(value), true
+}
+
+// Insert inserts the item, replacing the equal item.
+func (t *ItemTree) Insert(item btree.Item) {
+	value, err := t.codec.Marshal(item)
+	if err == nil {
+		err = t.tree.Put(t.key(item), value)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReplaceOrInsert inserts the item and returns the replaced equal item.
+func (t *ItemTree) ReplaceOrInsert(item btree.Item) (old btree.Item, replaced bool) {
+	old, replaced = t.Get(item)
+	t.Insert(item)
+	return
+}
+
+// Delete deletes the item equal to the item.
+func (t *ItemTree) Delete(item btree.Item) {
+	if _, err := t.tree.Delete(t.key(item)); err != nil {
+		panic(err)
+	}
+}
+
+// DeleteItem deletes the item equal to the item and returns it.
+func (t *ItemTree) DeleteItem(item btree.Item) (removed btree.Item, ok bool) {
+	if removed, ok = t.Get(item); ok {
+		t.Delete(item)
+	}
+	return
+}
+
+// Clear removes all items.
+func (t *ItemTree) Clear() {
+	var keys [][]byte
+	err := t.tree.Ascend(func(key, value []byte) bool {
+		keys = append(keys, append([]byte(nil), key...))
+		return true
+	})
+	for _, key := range keys {
+		if err != nil {
+			break
+		}
+		_, err = t.tree.Delete(key)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Ascend calls the fn for every item in ascending order until the fn returns false.
+func (t *ItemTree) Ascend(fn func(item btree.Item) bool) {
+	t.AscendRange(nil, nil, fn)
+}
+
+// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan)
+// in ascending order until the fn returns false. A nil bound leaves the range
+// unbounded on that side.
+func (t *ItemTree) AscendRange(greaterOrEqual, lessThan btree.Item, fn func(item btree.Item) bool) {
+	var lo, hi []byte
+	if greaterOrEqual != nil {
+		lo = t.key(greaterOrEqual)
+	}
+	if lessThan != nil {
+		hi = t.key(lessThan)
+	}
+	err := t.tree.AscendRange(lo, hi, func(key, value []byte) bool {
+		return fn(t.decode(value))
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (t *ItemTree) decode(value []byte) btree.Item {
+	item, err := t.codec.Unmarshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return item
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package disk
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hslam/btree"
+	"github.com/hslam/btree/btreetest"
+)
+
+// itemCodec encodes a btreetest.Item as its big-endian key and version.
+type itemCodec struct{}
+
+func itemKey(item btree.Item) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(item.(btreetest.Item).Key)^1<<63)
+	return b
+}
+
+func (itemCodec) Marshal(item btree.Item) ([]byte, error) {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(item.(btreetest.Item).Key))
+	binary.BigEndian.PutUint64(b[8:], uint64(item.(btreetest.Item).Version))
+	return b, nil
+}
+
+func (itemCodec) Unmarshal(data []byte) (btree.Item, error) {
+	if len(data) != 16 {
+		return nil, errors.New("bad item")
+	}
+	return btreetest.Item{
+		Key:     int(int64(binary.BigEndian.Uint64(data))),
+		Version: int(int64(binary.BigEndian.Uint64(data[8:]))),
+	}, nil
+}
+
+func TestConformance(t *testing.T) {
+	dir := t.TempDir()
+	var trees []*Tree
+	defer func() {
+		for _, tree := range trees {
+			tree.Close()
+		}
+	}()
+	btreetest.Run(t, func() btree.TreeInterface {
+		tree, err := Open(filepath.Join(dir, strconv.Itoa(len(trees))), MinPageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trees = append(trees, tree)
+		return NewItemTree(tree, itemKey, itemCodec{})
+	})
+}
+
+func TestItemTreePanic(t *testing.T) {
+	tree, err := Open(filepath.Join(t.TempDir(), "tree"), MinPageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := NewItemTree(tree, itemKey, itemCodec{})
+	if items.Tree() != tree {
+		t.Error("")
+	}
+	tree.Close()
+	for _, fn := range []func(){
+		func() { NewItemTree(nil, itemKey, itemCodec{}) },
+		func() { items.Insert(btreetest.Item{Key: 1}) },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package disk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+const (
+	freePage     = 0
+	leafPage     = 1
+	internalPage = 2
+)
+
+// The header of a node page holds the kind and the number of keys.
+const nodeHeaderSize = 3
+
+// node represents a decoded node page. A leaf holds the keys and the values,
+// and an internal node holds the keys and one more children than the keys.
+type node struct {
+	id       uint32
+	leaf     bool
+	keys     [][]byte
+	values   [][]byte
+	children []uint32
+}
+
+// search returns the index of the first key not less than the key and whether it is equal.
+func (n *node) search(key []byte) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(n.keys[i], key) >= 0
+	})
+	return i, i < len(n.keys) && bytes.Equal(n.keys[i], key)
+}
+
+// child returns the index of the child whose range holds the key.
+func (n *node) child(key []byte) int {
+	return sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(n.keys[i], key) > 0
+	})
+}
+
+// entrySize returns the encoded size of the i-th key with its value or right child.
+func (n *node) entrySize(i int) int {
+	if n.leaf {
+		return 4 + len(n.keys[i]) + len(n.values[i])
+	}
+	return 6 + len(n.keys[i])
+}
+
+func (n *node) size() int {
+	size := nodeHeaderSize
+	if !n.leaf {
+		size += 4
+	}
+	for i := range n.keys {
+		size += n.entrySize(i)
+	}
+	return size
+}
+
+// splitIndex returns the index splitting the keys into halves of about equal
+// encoded sizes, with at least one key on either side.
+func (n *node) splitIndex() int {
+	half, size := n.size()/2, nodeHeaderSize
+	for i := range n.keys {
+		if size += n.entrySize(i); size >= half {
+			if i == len(n.keys)-1 {
+				return i
+			}
+			return i + 1
+		}
+	}
+	return len(n.keys) / 2
+}
+
+func (n *node) encode(page []byte) {
+	for i := range page {
+		page[i] = 0
+	}
+	page[0] = internalPage
+	if n.leaf {
+		page[0] = leafPage
+	}
+	binary.LittleEndian.PutUint16(page[1:], uint16(len(n.keys)))
+	off := nodeHeaderSize
+	if !n.leaf {
+		binary.LittleEndian.PutUint32(page[off:], n.children[0])
+		off += 4
+	}
+	for i, key := range n.keys {
+		binary.LittleEndian.PutUint16(page[off:], uint16(len(key)))
+		off += 2
+		if n.leaf {
+			binary.LittleEndian.PutUint16(page[off:], uint16(len(n.values[i])))
+			off += 2
+		}
+		off += copy(page[off:], key)
+		if n.leaf {
+			off += copy(page[off:], n.values[i])
+		} else {
+			binary.LittleEndian.PutUint32(page[off:], n.children[i+1])
+			off += 4
+		}
+	}
+}
+
+func decode(id uint32, page []byte) (*node, error) {
+	if len(page) < nodeHeaderSize || page[0] != leafPage && page[0] != internalPage {
+		return nil, ErrCorrupt
+	}
+	n := &node{id: id, leaf: page[0] == leafPage}
+	count := int(binary.LittleEndian.Uint16(page[1:]))
+	off := nodeHeaderSize
+	next := func(size int) []byte {
+		if off+size > len(page) {
+			return nil
+		}
+		b := page[off : off+size : off+size]
+		off += size
+		return b
+	}
+	if !n.leaf {
+		b := next(4)
+		if b == nil {
+			return nil, ErrCorrupt
+		}
+		n.children = append(n.children, binary.LittleEndian.Uint32(b))
+	}
+	for i := 0; i < count; i++ {
+		b := next(2)
+		if b == nil {
+			return nil, ErrCorrupt
+		}
+		keySize, valueSize := int(binary.LittleEndian.Uint16(b)), 0
+		if n.leaf {
+			if b = next(2); b == nil {
+				return nil, ErrCorrupt
+			}
+			valueSize = int(binary.LittleEndian.Uint16(b))
+		}
+		key := next(keySize)
+		if key == nil {
+			return nil, ErrCorrupt
+		}
+		n.keys = append(n.keys, key)
+		if n.leaf {
+			value := next(valueSize)
+			if value == nil {
+				return nil, ErrCorrupt
+			}
+			n.values = append(n.values, value)
+		} else {
+			if b = next(4); b == nil {
+				return nil, ErrCorrupt
+			}
+			n.children = append(n.children, binary.LittleEndian.Uint32(b))
+		}
+	}
+	return n, nil
+}
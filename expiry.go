@@ -0,0 +1,197 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"time"
+)
+
+// ExpiryIndex tracks both the last access order and the expiry deadlines of
+// a set of items with B-trees kept in sync.
+type ExpiryIndex struct {
+	entries  *Tree
+	access   *Tree
+	deadline *Tree
+	tick     uint64
+	onEvict  func(item Item) (keep bool)
+	clock    Clock
+}
+
+type expiryEntry struct {
+	item     Item
+	tick     uint64
+	seq      uint64
+	deadline time.Time
+}
+
+func (a *expiryEntry) Less(b Item) bool {
+	return a.item.Less(b.(*expiryEntry).item)
+}
+
+type accessKey struct {
+	e *expiryEntry
+}
+
+func (a accessKey) Less(b Item) bool {
+	return a.e.tick < b.(accessKey).e.tick
+}
+
+type deadlineKey struct {
+	e *expiryEntry
+}
+
+func (a deadlineKey) Less(b Item) bool {
+	x, y := a.e, b.(deadlineKey).e
+	if x.deadline.Equal(y.deadline) {
+		return x.seq < y.seq
+	}
+	return x.deadline.Before(y.deadline)
+}
+
+// NewExpiryIndex returns a new expiry index with the given degree.
+func NewExpiryIndex(degree int) *ExpiryIndex {
+	return &ExpiryIndex{entries: New(degree), access: New(degree), deadline: New(degree)}
+}
+
+// Len returns the number of items in the index.
+func (x *ExpiryIndex) Len() int {
+	return x.entries.Length()
+}
+
+// Set sets the item with the time to live and marks it as the most recently
+// accessed. A non-positive ttl means the item never expires.
+func (x *ExpiryIndex) Set(item Item, ttl time.Duration) {
+	if item == nil {
+		panic("nil item being set to index")
+	}
+	if e := x.entry(item); e != nil {
+		x.remove(e)
+	}
+	x.tick++
+	e := &expiryEntry{item: item, tick: x.tick, seq: x.tick}
+	x.entries.Insert(e)
+	x.access.Insert(accessKey{e})
+	if ttl > 0 {
+		e.deadline = x.now().Add(ttl)
+		x.deadline.Insert(deadlineKey{e})
+	}
+}
+
+// Get returns the item equal to the given item and marks it as the most recently accessed.
+func (x *ExpiryIndex) Get(item Item) (Item, bool) {
+	e := x.entry(item)
+	if e == nil {
+		return nil, false
+	}
+	x.touch(e)
+	return e.item, true
+}
+
+// Touch marks the item as the most recently accessed.
+func (x *ExpiryIndex) Touch(item Item) bool {
+	e := x.entry(item)
+	if e == nil {
+		return false
+	}
+	x.touch(e)
+	return true
+}
+
+// Delete deletes the item from the index.
+func (x *ExpiryIndex) Delete(item Item) bool {
+	e := x.entry(item)
+	if e == nil {
+		return false
+	}
+	x.remove(e)
+	return true
+}
+
+// OnEvict sets the fn called with every item about to be evicted. If the fn
+// returns true, the item is kept and the evictor moves on to the next candidate.
+// The fn must not modify the index.
+func (x *ExpiryIndex) OnEvict(fn func(item Item) (keep bool)) {
+	x.onEvict = fn
+}
+
+// EvictExpired evicts the items whose deadlines have passed and returns them.
+// The items kept by the OnEvict fn stay expired and are offered again by the
+// next call.
+func (x *ExpiryIndex) EvictExpired() (evicted []Item) {
+	now := x.now()
+	var victims []*expiryEntry
+	x.deadline.Ascend(func(item Item) bool {
+		e := item.(deadlineKey).e
+		if e.deadline.After(now) {
+			return false
+		}
+		if !x.keep(e) {
+			victims = append(victims, e)
+		}
+		return true
+	})
+	return x.evict(victims)
+}
+
+// EvictLRU evicts at most n least recently accessed items and returns them.
+func (x *ExpiryIndex) EvictLRU(n int) (evicted []Item) {
+	if n <= 0 {
+		return
+	}
+	var victims []*expiryEntry
+	x.access.Ascend(func(item Item) bool {
+		e := item.(accessKey).e
+		if !x.keep(e) {
+			victims = append(victims, e)
+		}
+		return len(victims) < n
+	})
+	return x.evict(victims)
+}
+
+// SetClock sets the clock of the deadlines. A nil clock means the SystemClock.
+func (x *ExpiryIndex) SetClock(c Clock) {
+	x.clock = c
+}
+
+func (x *ExpiryIndex) now() time.Time {
+	if x.clock == nil {
+		return time.Now()
+	}
+	return x.clock.Now()
+}
+
+func (x *ExpiryIndex) keep(e *expiryEntry) bool {
+	return x.onEvict != nil && x.onEvict(e.item)
+}
+
+func (x *ExpiryIndex) evict(victims []*expiryEntry) (evicted []Item) {
+	for _, e := range victims {
+		x.remove(e)
+		evicted = append(evicted, e.item)
+	}
+	return
+}
+
+func (x *ExpiryIndex) entry(item Item) *expiryEntry {
+	if e := x.entries.Search(&expiryEntry{item: item}); e != nil {
+		return e.(*expiryEntry)
+	}
+	return nil
+}
+
+func (x *ExpiryIndex) touch(e *expiryEntry) {
+	x.access.Delete(accessKey{e})
+	x.tick++
+	e.tick = x.tick
+	x.access.Insert(accessKey{e})
+}
+
+func (x *ExpiryIndex) remove(e *expiryEntry) {
+	x.entries.Delete(e)
+	x.access.Delete(accessKey{e})
+	if !e.deadline.IsZero() {
+		x.deadline.Delete(deadlineKey{e})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiryIndex(t *testing.T) {
+	x := NewExpiryIndex(2)
+	for i := 0; i < 10; i++ {
+		x.Set(Int(i), 0)
+	}
+	x.Set(Int(10), time.Millisecond)
+	x.Set(Int(11), time.Hour)
+	if x.Len() != 12 {
+		t.Error(x.Len())
+	}
+	if item, ok := x.Get(Int(0)); !ok || item.(Int) != 0 {
+		t.Error(item)
+	}
+	if _, ok := x.Get(Int(-1)); ok {
+		t.Error("")
+	}
+	if !x.Touch(Int(1)) || x.Touch(Int(-1)) {
+		t.Error("")
+	}
+	evicted := x.EvictLRU(2)
+	if len(evicted) != 2 || evicted[0].(Int) != 2 || evicted[1].(Int) != 3 {
+		t.Error(evicted)
+	}
+	time.Sleep(time.Millisecond * 2)
+	evicted = x.EvictExpired()
+	if len(evicted) != 1 || evicted[0].(Int) != 10 {
+		t.Error(evicted)
+	}
+	if !x.Delete(Int(11)) || x.Delete(Int(11)) {
+		t.Error("")
+	}
+	x.Set(Int(4), time.Hour)
+	x.Set(Int(4), 0)
+	if len(x.EvictExpired()) != 0 || x.deadline.Length() != 0 {
+		t.Error("")
+	}
+	if x.Len() != 8 {
+		t.Error(x.Len())
+	}
+	evicted = x.EvictLRU(100)
+	if len(evicted) != 8 || evicted[0].(Int) != 5 || evicted[7].(Int) != 4 || x.Len() != 0 {
+		t.Error(evicted)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	x.Set(nil, 0)
+}
+
+func TestExpiryIndexOnEvict(t *testing.T) {
+	x := NewExpiryIndex(2)
+	for i := 0; i < 10; i++ {
+		x.Set(Int(i), 0)
+	}
+	x.Set(Int(10), time.Millisecond)
+	x.Set(Int(11), time.Millisecond)
+	var offered []Item
+	x.OnEvict(func(item Item) bool {
+		offered = append(offered, item)
+		return item.(Int)%2 == 0
+	})
+	evicted := x.EvictLRU(3)
+	if len(evicted) != 3 || evicted[0].(Int) != 1 || evicted[1].(Int) != 3 || evicted[2].(Int) != 5 {
+		t.Error(evicted)
+	}
+	if len(offered) != 6 {
+		t.Error(offered)
+	}
+	if len(x.EvictLRU(0)) != 0 {
+		t.Error("")
+	}
+	time.Sleep(time.Millisecond * 2)
+	evicted = x.EvictExpired()
+	if len(evicted) != 1 || evicted[0].(Int) != 11 || x.deadline.Length() != 1 {
+		t.Error(evicted)
+	}
+	x.OnEvict(nil)
+	evicted = x.EvictExpired()
+	if len(evicted) != 1 || evicted[0].(Int) != 10 || x.Len() != 7 {
+		t.Error(evicted)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Explain describes how a range query of a B-tree executes.
+type Explain struct {
+	// Height is the number of levels of the B-tree.
+	Height int
+	// EntryDepth is the level of the node holding the first item in the range,
+	// where the root is at level 1. It is 0 if the range is empty.
+	EntryDepth int
+	// Items is the number of the items in the range.
+	Items int
+	// Nodes is the estimated number of the nodes the range query touches.
+	Nodes int
+}
+
+// Explain describes how a range query of the B-tree for the range [lo, hi) executes.
+// A nil bound leaves the range unbounded on that side.
+func (t *Tree) Explain(lo, hi Item) Explain {
+	e := Explain{Height: t.root.Depth()}
+	if t.root == nil {
+		return e
+	}
+	start, end := 0, t.length
+	if lo != nil {
+		start = t.root.rank(lo)
+	}
+	if hi != nil {
+		end = t.root.rank(hi)
+	}
+	if end <= start {
+		e.Nodes = e.Height
+		return e
+	}
+	e.Items = end - start
+	e.EntryDepth = e.Height
+	if lo != nil {
+		e.EntryDepth = len(t.root.iterator(lo, false).path) + 1
+	}
+	perNode := (t.MinItems() + t.MaxItems() + 1) / 2
+	e.Nodes = e.Height + (e.Items-1)/perNode
+	return e
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tree := New(3)
+	if e := tree.Explain(Int(0), Int(10)); e != (Explain{}) {
+		t.Error(e)
+	}
+	for i := 0; i < 1000; i++ {
+		tree.Insert(Int(i))
+	}
+	height := tree.Root().Depth()
+	e := tree.Explain(Int(100), Int(200))
+	if e.Height != height || e.Items != 100 || e.EntryDepth < 1 || e.EntryDepth > height || e.Nodes < height {
+		t.Error(e)
+	}
+	if e := tree.Explain(nil, nil); e.Items != 1000 || e.EntryDepth != height {
+		t.Error(e)
+	}
+	if e := tree.Explain(Int(200), Int(100)); e.Items != 0 || e.EntryDepth != 0 || e.Nodes != height {
+		t.Error(e)
+	}
+	root := tree.Root().Items()[0]
+	if e := tree.Explain(root, nil); e.EntryDepth != 1 {
+		t.Error(e)
+	}
+}
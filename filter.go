@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Filter represents a predicate over items.
+type Filter interface {
+	// Match returns true if the item satisfies the filter.
+	Match(item Item) bool
+}
+
+// FilterFunc implements the Filter interface for a predicate function.
+type FilterFunc func(item Item) bool
+
+// Match returns f(item).
+func (f FilterFunc) Match(item Item) bool {
+	return f(item)
+}
+
+type and []Filter
+
+func (filters and) Match(item Item) bool {
+	for _, f := range filters {
+		if !f.Match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+type or []Filter
+
+func (filters or) Match(item Item) bool {
+	for _, f := range filters {
+		if f.Match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+type not struct {
+	f Filter
+}
+
+func (n not) Match(item Item) bool {
+	return !n.f.Match(item)
+}
+
+// And returns a filter matching the items matched by all the filters.
+func And(filters ...Filter) Filter {
+	return and(filters)
+}
+
+// Or returns a filter matching the items matched by any of the filters.
+func Or(filters ...Filter) Filter {
+	return or(filters)
+}
+
+// Not returns a filter matching the items not matched by the filter.
+func Not(filter Filter) Filter {
+	return not{filter}
+}
+
+// AscendFiltered calls the fn for every item matched by the filter in the range
+// [lo, hi) of the B-tree in ascending order until the fn returns false.
+// A nil bound leaves the range unbounded on that side.
+func (t *Tree) AscendFiltered(lo, hi Item, filter Filter, fn func(item Item) bool) {
+	t.root.ascendRange(lo, hi, func(item Item) bool {
+		if filter != nil && !filter.Match(item) {
+			return true
+		}
+		return fn(item)
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestAscendFiltered(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	even := FilterFunc(func(item Item) bool { return item.(Int)%2 == 0 })
+	triple := FilterFunc(func(item Item) bool { return item.(Int)%3 == 0 })
+	collect := func(lo, hi Item, filter Filter) (items []int) {
+		tree.AscendFiltered(lo, hi, filter, func(item Item) bool {
+			items = append(items, int(item.(Int)))
+			return true
+		})
+		return
+	}
+	if items := collect(Int(0), Int(20), even); len(items) != 10 || items[1] != 2 {
+		t.Error(items)
+	}
+	if items := collect(Int(0), Int(20), And(even, triple)); len(items) != 4 || items[1] != 6 {
+		t.Error(items)
+	}
+	if items := collect(Int(0), Int(20), Or(even, triple)); len(items) != 13 {
+		t.Error(items)
+	}
+	if items := collect(Int(0), Int(20), Not(Or(even, triple))); len(items) != 7 || items[0] != 1 {
+		t.Error(items)
+	}
+	if items := collect(nil, nil, nil); len(items) != 100 {
+		t.Error(items)
+	}
+	count := 0
+	tree.AscendFiltered(nil, nil, even, func(item Item) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Error(count)
+	}
+}
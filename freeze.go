@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// ReadOnlyTree represents a read-only B-tree.
+type ReadOnlyTree interface {
+	// Length returns the number of items.
+	Length() int
+	// Root returns the root node.
+	Root() *Node
+	// Max returns the max node.
+	Max() *Node
+	// Min returns the min node.
+	Min() *Node
+	// MaxItem returns the max item.
+	MaxItem() Item
+	// MinItem returns the min item.
+	MinItem() Item
+	// Search searches the item.
+	Search(item Item) Item
+	// Get returns the item equal to the item and whether it was found.
+	Get(item Item) (Item, bool)
+	// SearchNode searches the node with the item.
+	SearchNode(item Item) *Node
+	// SearchIterator searches the iterator with the item.
+	SearchIterator(item Item) *Iterator
+	// Ascend calls the fn for every item in ascending order.
+	Ascend(fn func(item Item) bool)
+	// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan) in ascending order.
+	AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool)
+	// AscendGreaterOrEqual calls the fn for every item greater than or equal to the pivot in ascending order.
+	AscendGreaterOrEqual(pivot Item, fn func(item Item) bool)
+	// Descend calls the fn for every item in descending order.
+	Descend(fn func(item Item) bool)
+	// DescendRange calls the fn for every item in the range (greaterThan, lessOrEqual] in descending order.
+	DescendRange(lessOrEqual, greaterThan Item, fn func(item Item) bool)
+	// DescendLessOrEqual calls the fn for every item less than or equal to the pivot in descending order.
+	DescendLessOrEqual(pivot Item, fn func(item Item) bool)
+}
+
+// Freeze returns an immutable read-only view of the B-tree. The view shares
+// the nodes with the B-tree, which copies the shared nodes on the paths of its
+// mutations, so the iterators of the min and max nodes of the view still cover
+// the whole view.
+func (t *Tree) Freeze() ReadOnlyTree {
+	return frozen{t: t.Clone()}
+}
+
+// frozen wraps a tree without exposing its mutating methods.
+type frozen struct {
+	t *Tree
+}
+
+func (f frozen) Length() int {
+	return f.t.Length()
+}
+
+func (f frozen) Root() *Node {
+	return f.t.Root()
+}
+
+func (f frozen) Max() *Node {
+	return f.t.Max()
+}
+
+func (f frozen) Min() *Node {
+	return f.t.Min()
+}
+
+func (f frozen) MaxItem() Item {
+	return f.t.MaxItem()
+}
+
+func (f frozen) MinItem() Item {
+	return f.t.MinItem()
+}
+
+func (f frozen) Search(item Item) Item {
+	return f.t.Search(item)
+}
+
+func (f frozen) Get(item Item) (Item, bool) {
+	return f.t.Get(item)
+}
+
+func (f frozen) SearchNode(item Item) *Node {
+	return f.t.SearchNode(item)
+}
+
+func (f frozen) SearchIterator(item Item) *Iterator {
+	return f.t.SearchIterator(item)
+}
+
+func (f frozen) Ascend(fn func(item Item) bool) {
+	f.t.Ascend(fn)
+}
+
+func (f frozen) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool) {
+	f.t.AscendRange(greaterOrEqual, lessThan, fn)
+}
+
+func (f frozen) AscendGreaterOrEqual(pivot Item, fn func(item Item) bool) {
+	f.t.AscendGreaterOrEqual(pivot, fn)
+}
+
+func (f frozen) Descend(fn func(item Item) bool) {
+	f.t.Descend(fn)
+}
+
+func (f frozen) DescendRange(lessOrEqual, greaterThan Item, fn func(item Item) bool) {
+	f.t.DescendRange(lessOrEqual, greaterThan, fn)
+}
+
+func (f frozen) DescendLessOrEqual(pivot Item, fn func(item Item) bool) {
+	f.t.DescendLessOrEqual(pivot, fn)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	view := tree.Freeze()
+	if _, ok := view.(interface{ Insert(Item) }); ok {
+		t.Error("")
+	}
+	tree.Delete(Int(50))
+	tree.Insert(Int(100))
+	if view.Length() != 100 || view.Root() == nil || view.Min().Items()[0].(Int) != 0 || view.Max() == nil {
+		t.Error("")
+	}
+	if view.MinItem().(Int) != 0 || view.MaxItem().(Int) != 99 {
+		t.Error("")
+	}
+	if view.Search(Int(50)) == nil || view.Search(Int(100)) != nil {
+		t.Error("")
+	}
+	if item, ok := view.Get(Int(50)); !ok || item.(Int) != 50 {
+		t.Error("")
+	}
+	if view.SearchNode(Int(50)) == nil || view.SearchIterator(Int(50)).Item().(Int) != 50 {
+		t.Error("")
+	}
+	count := 0
+	fn := func(item Item) bool {
+		count++
+		return true
+	}
+	view.Ascend(fn)
+	view.AscendRange(Int(10), Int(20), fn)
+	view.AscendGreaterOrEqual(Int(90), fn)
+	view.Descend(fn)
+	view.DescendRange(Int(20), Int(10), fn)
+	view.DescendLessOrEqual(Int(9), fn)
+	if count != 100+10+10+100+10+10 {
+		t.Error(count)
+	}
+	count = 0
+	for iter := view.Min().MinIterator(); iter != nil; iter = iter.Next() {
+		count++
+	}
+	for iter := view.Max().MaxIterator(); iter != nil; iter = iter.Last() {
+		count++
+	}
+	if count != 200 {
+		t.Error(count)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package btree
+
+// FuncTree represents a B-tree of values of any type ordered by a less func
+// or a three-way compare func, for types without a Less method.
+type FuncTree[T any] struct {
+	tree *Tree
+	less func(a, b T) bool
+	cmp  func(a, b T) int
+}
+
+type funcItem[T any] struct {
+	v T
+	t *FuncTree[T]
+}
+
+func (a funcItem[T]) Less(b Item) bool {
+	return a.t.less(a.v, b.(funcItem[T]).v)
+}
+
+type cmpItem[T any] struct {
+	v T
+	t *FuncTree[T]
+}
+
+func (a cmpItem[T]) Less(b Item) bool {
+	return a.t.cmp(a.v, b.(cmpItem[T]).v) < 0
+}
+
+func (a cmpItem[T]) Compare(b Item) int {
+	return a.t.cmp(a.v, b.(cmpItem[T]).v)
+}
+
+// NewFunc returns a new FuncTree with the given degree ordering the values by the less func.
+func NewFunc[T any](degree int, less func(a, b T) bool) *FuncTree[T] {
+	if less == nil {
+		panic("nil less func")
+	}
+	return &FuncTree[T]{tree: New(degree), less: less}
+}
+
+// NewCmp returns a new FuncTree with the given degree ordering the values by
+// the cmp func, which returns a negative number if a < b, zero if a == b and a
+// positive number otherwise. A search compares each value once per probe.
+func NewCmp[T any](degree int, cmp func(a, b T) int) *FuncTree[T] {
+	if cmp == nil {
+		panic("nil cmp func")
+	}
+	return &FuncTree[T]{tree: New(degree), cmp: cmp}
+}
+
+func (t *FuncTree[T]) item(v T) Item {
+	if t.cmp != nil {
+		return cmpItem[T]{v: v, t: t}
+	}
+	return funcItem[T]{v: v, t: t}
+}
+
+func (t *FuncTree[T]) value(item Item) T {
+	if t.cmp != nil {
+		return item.(cmpItem[T]).v
+	}
+	return item.(funcItem[T]).v
+}
+
+// Len returns the number of values in the tree.
+func (t *FuncTree[T]) Len() int {
+	return t.tree.Length()
+}
+
+// ReplaceOrInsert inserts the value and returns the replaced equal value.
+func (t *FuncTree[T]) ReplaceOrInsert(v T) (old T, replaced bool) {
+	if item, ok := t.tree.ReplaceOrInsert(t.item(v)); ok {
+		return t.value(item), true
+	}
+	return
+}
+
+// Get returns the value equal to the v and whether it was found.
+func (t *FuncTree[T]) Get(v T) (found T, ok bool) {
+	if item := t.tree.Search(t.item(v)); item != nil {
+		return t.value(item), true
+	}
+	return
+}
+
+// Delete deletes the value equal to the v and returns it.
+func (t *FuncTree[T]) Delete(v T) (removed T, ok bool) {
+	if item, ok := t.tree.DeleteItem(t.item(v)); ok {
+		return t.value(item), true
+	}
+	return
+}
+
+// Min returns the min value, and false if the tree is empty.
+func (t *FuncTree[T]) Min() (v T, ok bool) {
+	if item := t.tree.MinItem(); item != nil {
+		return t.value(item), true
+	}
+	return
+}
+
+// Max returns the max value, and false if the tree is empty.
+func (t *FuncTree[T]) Max() (v T, ok bool) {
+	if item := t.tree.MaxItem(); item != nil {
+		return t.value(item), true
+	}
+	return
+}
+
+// Ascend calls the fn for every value in ascending order until the fn returns false.
+func (t *FuncTree[T]) Ascend(fn func(v T) bool) {
+	t.tree.Ascend(func(item Item) bool {
+		return fn(t.value(item))
+	})
+}
+
+// AscendRange calls the fn for every value in the range [greaterOrEqual, lessThan)
+// in ascending order until the fn returns false.
+func (t *FuncTree[T]) AscendRange(greaterOrEqual, lessThan T, fn func(v T) bool) {
+	t.tree.AscendRange(t.item(greaterOrEqual), t.item(lessThan), func(item Item) bool {
+		return fn(t.value(item))
+	})
+}
+
+// Descend calls the fn for every value in descending order until the fn returns false.
+func (t *FuncTree[T]) Descend(fn func(v T) bool) {
+	t.tree.Descend(func(item Item) bool {
+		return fn(t.value(item))
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package btree
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestFuncTree(t *testing.T) {
+	type user struct {
+		id   int
+		name string
+	}
+	tree := NewFunc(2, func(a, b user) bool { return a.id < b.id })
+	if _, ok := tree.Min(); ok {
+		t.Error("")
+	}
+	if _, ok := tree.Max(); ok {
+		t.Error("")
+	}
+	for i := 0; i < 50; i++ {
+		if _, replaced := tree.ReplaceOrInsert(user{id: i}); replaced {
+			t.Error(i)
+		}
+	}
+	if old, replaced := tree.ReplaceOrInsert(user{id: 7, name: "g"}); !replaced || old.name != "" {
+		t.Error(old)
+	}
+	if u, ok := tree.Get(user{id: 7}); !ok || u.name != "g" || tree.Len() != 50 {
+		t.Error(u)
+	}
+	if _, ok := tree.Get(user{id: 50}); ok {
+		t.Error("")
+	}
+	if u, ok := tree.Delete(user{id: 7}); !ok || u.name != "g" {
+		t.Error(u)
+	}
+	if _, ok := tree.Delete(user{id: 7}); ok || tree.Len() != 49 {
+		t.Error("")
+	}
+	if u, ok := tree.Min(); !ok || u.id != 0 {
+		t.Error(u)
+	}
+	if u, ok := tree.Max(); !ok || u.id != 49 {
+		t.Error(u)
+	}
+	count := 0
+	tree.Ascend(func(u user) bool {
+		count++
+		return true
+	})
+	tree.AscendRange(user{id: 5}, user{id: 10}, func(u user) bool {
+		count++
+		return true
+	})
+	tree.Descend(func(u user) bool {
+		if u.id != 49 {
+			t.Error(u)
+		}
+		return false
+	})
+	if count != 49+4 {
+		t.Error(count)
+	}
+	ips := NewFunc(2, func(a, b net.IP) bool { return string(a.To16()) < string(b.To16()) })
+	ips.ReplaceOrInsert(net.ParseIP("10.0.0.2"))
+	ips.ReplaceOrInsert(net.ParseIP("10.0.0.1"))
+	if ip, _ := ips.Min(); !ip.Equal(net.ParseIP("10.0.0.1")) {
+		t.Error(ip)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	NewFunc[int](2, nil)
+}
+
+func TestCmpTree(t *testing.T) {
+	calls := 0
+	tree := NewCmp(3, func(a, b string) int {
+		calls++
+		return strings.Compare(a, b)
+	})
+	for i := 0; i < 1000; i++ {
+		tree.ReplaceOrInsert(fmt.Sprintf("%04d", i))
+	}
+	if tree.Len() != 1000 || tree.tree.Validate() != nil {
+		t.Error(tree.Len())
+	}
+	calls = 0
+	if v, ok := tree.Get("0500"); !ok || v != "0500" {
+		t.Error(v)
+	}
+	if calls > 20 {
+		t.Error(calls)
+	}
+	if old, replaced := tree.ReplaceOrInsert("0001"); !replaced || old != "0001" {
+		t.Error(old)
+	}
+	if v, ok := tree.Delete("0001"); !ok || v != "0001" || tree.Len() != 999 {
+		t.Error(v)
+	}
+	if v, ok := tree.Min(); !ok || v != "0000" {
+		t.Error(v)
+	}
+	var values []string
+	tree.AscendRange("0000", "0003", func(v string) bool {
+		values = append(values, v)
+		return true
+	})
+	if len(values) != 2 || values[1] != "0002" {
+		t.Error(values)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	NewCmp[int](2, nil)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// generations records the generation stamped on every stored key.
+type generations struct {
+	seq     uint64
+	entries *Tree
+}
+
+type generationEntry struct {
+	item Item
+	gen  uint64
+}
+
+func (a *generationEntry) Less(b Item) bool {
+	return a.item.Less(b.(*generationEntry).item)
+}
+
+// EnableGenerations enables stamping a generation on every key of the B-tree
+// when it is inserted, replaced or updated, so that external caches can
+// validate their entries by the generation instead of comparing the items.
+// The generations increase across all keys and are never reused, even after a
+// key is deleted. The generations are not inherited by clones.
+func (t *Tree) EnableGenerations() {
+	t.gens = &generations{entries: New(t.degree)}
+	t.Ascend(func(item Item) bool {
+		t.gens.stamp(item)
+		return true
+	})
+}
+
+// GetWithGeneration returns the stored item equal to the given item with its
+// generation, and whether it was found.
+func (t *Tree) GetWithGeneration(item Item) (Item, uint64, bool) {
+	e := t.generation(item)
+	if e == nil {
+		return nil, 0, false
+	}
+	return e.item, e.gen, true
+}
+
+// ReplaceIfGeneration replaces the item equal to the given item with it only
+// if the generation of the key is gen, and returns whether it was replaced.
+// A zero gen inserts the item only if the key is absent.
+func (t *Tree) ReplaceIfGeneration(item Item, gen uint64) bool {
+	if item == nil {
+		panic("nil item being inserted to tree")
+	}
+	var current uint64
+	if e := t.generation(item); e != nil {
+		current = e.gen
+	}
+	if current != gen {
+		return false
+	}
+	t.insert(item, true)
+	return true
+}
+
+func (t *Tree) generation(item Item) *generationEntry {
+	if t.gens == nil {
+		panic("generations not enabled")
+	}
+	if e := t.gens.entries.Search(&generationEntry{item: item}); e != nil {
+		return e.(*generationEntry)
+	}
+	return nil
+}
+
+func (g *generations) stamp(item Item) {
+	g.seq++
+	if e := g.entries.Search(&generationEntry{item: item}); e != nil {
+		e := e.(*generationEntry)
+		e.item, e.gen = item, g.seq
+		return
+	}
+	g.entries.Insert(&generationEntry{item: item, gen: g.seq})
+}
+
+func (g *generations) forget(item Item) {
+	g.entries.Delete(&generationEntry{item: item})
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestGenerations(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 10; i++ {
+		tree.Insert(tagged{key: i})
+	}
+	tree.EnableGenerations()
+	item, gen, ok := tree.GetWithGeneration(tagged{key: 3})
+	if !ok || item.(tagged).key != 3 || gen != 4 {
+		t.Error(gen)
+	}
+	if _, _, ok := tree.GetWithGeneration(tagged{key: 10}); ok {
+		t.Error("")
+	}
+	if tree.ReplaceIfGeneration(tagged{key: 3, tag: "1"}, gen-1) {
+		t.Error("")
+	}
+	if !tree.ReplaceIfGeneration(tagged{key: 3, tag: "1"}, gen) {
+		t.Error("")
+	}
+	item, next, _ := tree.GetWithGeneration(tagged{key: 3})
+	if item.(tagged).tag != "1" || next <= gen {
+		t.Error(next)
+	}
+	if tree.ReplaceIfGeneration(tagged{key: 3, tag: "2"}, gen) {
+		t.Error("")
+	}
+	if tree.ReplaceIfGeneration(tagged{key: 10}, 1) || !tree.ReplaceIfGeneration(tagged{key: 10}, 0) {
+		t.Error("")
+	}
+	if tree.ReplaceIfGeneration(tagged{key: 10}, 0) || tree.Length() != 11 {
+		t.Error(tree.Length())
+	}
+	tree.Update(tagged{key: 4}, func(old Item, found bool) (Item, bool) {
+		return tagged{key: 4, tag: "4"}, true
+	})
+	if _, gen, _ := tree.GetWithGeneration(tagged{key: 4}); gen != 13 {
+		t.Error(gen)
+	}
+	tree.Delete(tagged{key: 4})
+	if _, _, ok := tree.GetWithGeneration(tagged{key: 4}); ok {
+		t.Error("")
+	}
+	tree.Insert(tagged{key: 4})
+	if _, gen, _ := tree.GetWithGeneration(tagged{key: 4}); gen != 14 {
+		t.Error(gen)
+	}
+	tree.DeleteRange(tagged{key: 0}, tagged{key: 5})
+	if _, _, ok := tree.GetWithGeneration(tagged{key: 2}); ok || tree.gens.entries.Length() != tree.Length() {
+		t.Error(tree.gens.entries.Length())
+	}
+	tree.Clear()
+	if tree.ReplaceIfGeneration(tagged{key: 5}, 9) || !tree.ReplaceIfGeneration(tagged{key: 5}, 0) {
+		t.Error("")
+	}
+	if _, gen, _ := tree.GetWithGeneration(tagged{key: 5}); gen != 15 {
+		t.Error(gen)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	New(2).GetWithGeneration(Int(1))
+}
+
+func TestGenerationsDeleteAndNext(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 100; i++ {
+		tree.Insert(&pointer{i})
+	}
+	tree.EnableGenerations()
+	tree.EnableKeyCheck(func(item Item) interface{} {
+		return item.(*pointer).value
+	})
+	for i := 0; i < 100; i += 3 {
+		iter, ok := tree.DeleteAndNext(&pointer{i})
+		if !ok || i < 99 && iter.Item().(*pointer).value != i+1 {
+			t.Error(i)
+		}
+		if _, _, ok := tree.GetWithGeneration(&pointer{i}); ok {
+			t.Error(i)
+		}
+		if _, gen, ok := tree.GetWithGeneration(&pointer{i + 1}); i < 99 && (!ok || gen != uint64(i+2)) {
+			t.Error(i, gen)
+		}
+	}
+	if tree.gens.entries.Length() != tree.Length() || len(tree.keys.keys) != tree.Length() {
+		t.Error(tree.gens.entries.Length(), len(tree.keys.keys), tree.Length())
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"sort"
+)
+
+// ErrNilItem is returned when the decode func of ImportGob returns a nil item.
+var ErrNilItem = errors.New("nil item decoded")
+
+// ExportGob writes the items of the B-tree to the w in ascending order as a
+// stream of gob values, the same as a dump of a google/btree taken by encoding
+// the items visited by its Ascend. The concrete types of the items must be
+// encodable by gob.
+func (t *Tree) ExportGob(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	t.Ascend(func(item Item) bool {
+		err = enc.Encode(item)
+		return err == nil
+	})
+	return
+}
+
+// ImportGob returns a new B-tree with the given degree holding the items read
+// from a stream of gob values, such as a dump of a google/btree or one written
+// by ExportGob. The decode func decodes the next value from the decoder into an
+// item of this package. The items need not be sorted; of the equal items the
+// last one is kept. It returns ErrNilItem if the decode func returns a nil item
+// without an error. The B-tree can then be saved with MarshalBinary.
+func ImportGob(degree int, r io.Reader, decode func(dec *gob.Decoder) (Item, error)) (*Tree, error) {
+	dec := gob.NewDecoder(r)
+	var items []Item
+	for {
+		item, err := decode(dec)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, ErrNilItem
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Less(items[j])
+	})
+	sorted := items[:0]
+	for _, item := range items {
+		if n := len(sorted); n > 0 && !sorted[n-1].Less(item) {
+			sorted[n-1] = item
+			continue
+		}
+		sorted = append(sorted, item)
+	}
+	return LoadSorted(degree, sorted), nil
+}
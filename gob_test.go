@@ -0,0 +1,67 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+)
+
+func decodeInt(dec *gob.Decoder) (Item, error) {
+	var v int
+	err := dec.Decode(&v)
+	return Int(v), err
+}
+
+func TestGob(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	var buf bytes.Buffer
+	if err := tree.ExportGob(&buf); err != nil {
+		t.Error(err)
+	}
+	dump := buf.Bytes()
+	imported, err := ImportGob(2, bytes.NewReader(dump), decodeInt)
+	if err != nil {
+		t.Error(err)
+	}
+	testTraversal(imported, t)
+	if imported.Length() != 100 || imported.MinItem().(Int) != 0 || imported.MaxItem().(Int) != 99 {
+		t.Error(imported.Length())
+	}
+	buf.Reset()
+	enc := gob.NewEncoder(&buf)
+	for _, v := range []int{3, 1, 2, 1} {
+		enc.Encode(v)
+	}
+	imported, err = ImportGob(2, &buf, decodeInt)
+	if err != nil || imported.Length() != 3 {
+		t.Error(err)
+	}
+	if _, err := ImportGob(2, bytes.NewReader(dump[:len(dump)-1]), decodeInt); err == nil {
+		t.Error("")
+	}
+	if _, err := ImportGob(2, bytes.NewReader(dump), func(dec *gob.Decoder) (Item, error) {
+		return nil, errors.New("decode")
+	}); err == nil {
+		t.Error("")
+	}
+	if _, err := ImportGob(2, bytes.NewReader(dump), func(dec *gob.Decoder) (Item, error) {
+		return nil, nil
+	}); err != ErrNilItem {
+		t.Error(err)
+	}
+	if err := New(2).ExportGob(&buf); err != nil {
+		t.Error(err)
+	}
+	tree = New(2)
+	tree.Insert(&pointer{})
+	if err := tree.ExportGob(&buf); err == nil {
+		t.Error("")
+	}
+}
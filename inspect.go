@@ -0,0 +1,152 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Stats represents the shape statistics of a B-tree.
+type Stats struct {
+	// Length is the number of items.
+	Length int `json:"length"`
+	// Height is the number of levels.
+	Height int `json:"height"`
+	// Nodes is the number of nodes.
+	Nodes int `json:"nodes"`
+	// Leaves is the number of leaf nodes.
+	Leaves int `json:"leaves"`
+	// Fill is the average ratio of the number of items to the max number of items of the nodes.
+	Fill float64 `json:"fill"`
+}
+
+// Stats returns the shape statistics of the B-tree.
+func (t *Tree) Stats() Stats {
+	s := Stats{Length: t.length, Height: t.root.Depth()}
+	var fill float64
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		s.Nodes++
+		fill += n.Fill()
+		if len(n.children) == 0 {
+			s.Leaves++
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	if t.root != nil {
+		walk(t.root)
+		s.Fill = fill / float64(s.Nodes)
+	}
+	return s
+}
+
+// Validate checks the invariants of the B-tree and returns an error describing
+// the first violation found. The parent links of the nodes shared with clones
+// and of the orphans are not checked, except that the paths to the min and max
+// leaves must be unshared.
+func (t *Tree) Validate() error {
+	if t.root == nil {
+		if t.length != 0 {
+			return fmt.Errorf("length %d of empty tree", t.length)
+		}
+		return nil
+	}
+	if t.root.parent != nil {
+		return fmt.Errorf("root with parent")
+	}
+	if t.root.size != t.length {
+		return fmt.Errorf("length %d not equal to root size %d", t.length, t.root.size)
+	}
+	if t.minLeaf != t.root.min() || t.maxLeaf != t.root.max() {
+		return fmt.Errorf("stale min or max leaf")
+	}
+	height := t.root.Depth()
+	var check func(n *Node, depth int, lo, hi Item) error
+	check = func(n *Node, depth int, lo, hi Item) error {
+		if refs := atomic.LoadInt32(&n.refs); refs < 1 {
+			return fmt.Errorf("node at depth %d with %d refs", depth, refs)
+		}
+		if len(n.items) > t.MaxItems() || len(n.items) < 1 || n != t.root && len(n.items) < t.MinItems() {
+			return fmt.Errorf("node at depth %d with %d items", depth, len(n.items))
+		}
+		for i, item := range n.items {
+			if lo != nil && !lo.Less(item) || i > 0 && !n.items[i-1].Less(item) {
+				return fmt.Errorf("item %v out of order at depth %d", item, depth)
+			}
+		}
+		if hi != nil && !n.items[len(n.items)-1].Less(hi) {
+			return fmt.Errorf("item %v out of order at depth %d", n.items[len(n.items)-1], depth)
+		}
+		size := len(n.items)
+		if len(n.children) == 0 {
+			if depth != height {
+				return fmt.Errorf("leaf at depth %d of tree with height %d", depth, height)
+			}
+		} else if len(n.children) != len(n.items)+1 {
+			return fmt.Errorf("node at depth %d with %d items and %d children", depth, len(n.items), len(n.children))
+		}
+		for i, child := range n.children {
+			if child.linked() && child.parent != n {
+				return fmt.Errorf("bad parent of node at depth %d", depth+1)
+			}
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = n.items[i-1]
+			}
+			if i < len(n.items) {
+				childHi = n.items[i]
+			}
+			if err := check(child, depth+1, childLo, childHi); err != nil {
+				return err
+			}
+			size += child.size
+		}
+		if size != n.size {
+			return fmt.Errorf("node at depth %d with size %d, want %d", depth, n.size, size)
+		}
+		return nil
+	}
+	if err := check(t.root, 1, nil, nil); err != nil {
+		return err
+	}
+	for _, leaf := range []*Node{t.minLeaf, t.maxLeaf} {
+		for n := leaf; n != t.root; n = n.parent {
+			if !n.linked() {
+				return fmt.Errorf("shared node on the path to the min or max leaf")
+			}
+		}
+	}
+	return nil
+}
+
+// OrderError describes the first pair of adjacent items out of order.
+type OrderError struct {
+	Prev Item
+	Next Item
+}
+
+func (e *OrderError) Error() string {
+	return fmt.Sprintf("item %v not less than next item %v", e.Prev, e.Next)
+}
+
+// AuditOrder scans all items of the B-tree in order and returns an *OrderError
+// with the first adjacent pair not in strictly ascending order. Since the scan
+// visits every separator between the items of its child subtrees, it also
+// checks the separators. It is cheaper than Validate.
+func (t *Tree) AuditOrder() error {
+	var err error
+	var prev Item
+	t.root.ascend(func(item Item) bool {
+		if prev != nil && !prev.Less(item) {
+			err = &OrderError{Prev: prev, Next: item}
+			return false
+		}
+		prev = item
+		return true
+	})
+	return err
+}
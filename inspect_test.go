@@ -0,0 +1,94 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tree := New(2)
+	if s := tree.Stats(); s.Nodes != 0 || s.Height != 0 || s.Fill != 0 {
+		t.Error(s)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	s := tree.Stats()
+	if s.Length != 100 || s.Height != tree.Root().Depth() || s.Leaves == 0 || s.Nodes <= s.Leaves {
+		t.Error(s)
+	}
+	if s.Fill <= 0 || s.Fill > 1 {
+		t.Error(s.Fill)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := New(2)
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
+	corrupt := func(fn func(tree *Tree)) {
+		c := tree.Clone()
+		c.mutate()
+		fn(c)
+		if err := c.Validate(); err == nil {
+			t.Error("")
+		}
+	}
+	corrupt(func(tree *Tree) { tree.length++ })
+	corrupt(func(tree *Tree) { tree.minLeaf = tree.maxLeaf })
+	corrupt(func(tree *Tree) { tree.root.parent = tree.root })
+	corrupt(func(tree *Tree) { tree.root.min().items[0] = Int(1000) })
+	corrupt(func(tree *Tree) { tree.root.max().items[0] = Int(-1) })
+	corrupt(func(tree *Tree) { tree.root.items[0] = Int(1000) })
+	corrupt(func(tree *Tree) { tree.root.children[0].parent = nil })
+	corrupt(func(tree *Tree) { tree.root.children[0].size++ })
+	corrupt(func(tree *Tree) { tree.root.children = tree.root.children[:1] })
+	corrupt(func(tree *Tree) {
+		leaf := tree.root.max()
+		leaf.items = leaf.items[:0]
+	})
+	corrupt(func(tree *Tree) {
+		leaf := tree.root.min()
+		leaf.children = append(leaf.children, newNode(tree.MaxItems()))
+	})
+	empty := New(2)
+	empty.length = 1
+	if err := empty.Validate(); err == nil {
+		t.Error("")
+	}
+}
+
+func TestAuditOrder(t *testing.T) {
+	tree := New(2)
+	if err := tree.AuditOrder(); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	if err := tree.AuditOrder(); err != nil {
+		t.Error(err)
+	}
+	tree.root.items[0] = Int(1000)
+	err := tree.AuditOrder()
+	if e, ok := err.(*OrderError); !ok || e.Prev.(Int) != 1000 || e.Error() == "" {
+		t.Error(err)
+	}
+	tree = New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	tree.root.min().items[0] = Int(50)
+	if e, ok := tree.AuditOrder().(*OrderError); !ok || e.Prev.(Int) != 50 || e.Next.(Int) != 1 {
+		t.Error(e)
+	}
+}
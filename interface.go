@@ -0,0 +1,36 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// TreeInterface represents the public behavior shared by the B-tree variants.
+// The btreetest package checks an implementation against it.
+type TreeInterface interface {
+	// Length returns the number of items.
+	Length() int
+	// Search returns the item equal to the item, or nil if there is none.
+	Search(item Item) Item
+	// Get returns the item equal to the item and whether it was found.
+	Get(item Item) (Item, bool)
+	// Insert inserts the item, replacing the equal item.
+	Insert(item Item)
+	// ReplaceOrInsert inserts the item and returns the replaced equal item.
+	ReplaceOrInsert(item Item) (old Item, replaced bool)
+	// Delete deletes the item equal to the item.
+	Delete(item Item)
+	// DeleteItem deletes the item equal to the item and returns it.
+	DeleteItem(item Item) (removed Item, ok bool)
+	// Clear removes all items.
+	Clear()
+	// Ascend calls the fn for every item in ascending order until the fn returns false.
+	Ascend(fn func(item Item) bool)
+	// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan)
+	// in ascending order until the fn returns false.
+	AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool)
+}
+
+var (
+	_ TreeInterface = (*Tree)(nil)
+	_ TreeInterface = (*SyncTree)(nil)
+	_ TreeInterface = (*ShardedTree)(nil)
+)
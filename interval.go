@@ -0,0 +1,94 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Interval represents a half-open interval [Start, End) stored in an IntervalTree.
+// The intervals must be ordered by Less first by their starts.
+type Interval interface {
+	Item
+	// Start returns the inclusive start of the interval.
+	Start() Item
+	// End returns the exclusive end of the interval.
+	End() Item
+}
+
+// IntervalTree represents a B-tree of intervals augmented with the max end of
+// every subtree, which answers the overlap queries in O(log n + k) time.
+type IntervalTree struct {
+	tree *Tree
+}
+
+// NewInterval returns a new IntervalTree with the given degree.
+func NewInterval(degree int) *IntervalTree {
+	t := &IntervalTree{tree: New(degree)}
+	t.tree.EnableAggregate(Aggregate{
+		FromItem: func(item Item) interface{} {
+			return item.(Interval).End()
+		},
+		Combine: func(a, b interface{}) interface{} {
+			if a == nil || b != nil && a.(Item).Less(b.(Item)) {
+				return b
+			}
+			return a
+		},
+	})
+	return t
+}
+
+// Length returns the number of intervals in the tree.
+func (t *IntervalTree) Length() int {
+	return t.tree.Length()
+}
+
+// Insert inserts the interval into the tree, replacing the equal interval.
+func (t *IntervalTree) Insert(iv Interval) {
+	t.tree.Insert(iv)
+}
+
+// Delete deletes the interval equal to the iv and reports whether it existed.
+func (t *IntervalTree) Delete(iv Interval) bool {
+	_, ok := t.tree.DeleteItem(iv)
+	return ok
+}
+
+// Overlaps calls the fn for every interval overlapping the range [lo, hi) in
+// ascending order until the fn returns false.
+func (t *IntervalTree) Overlaps(lo, hi Item, fn func(iv Interval) bool) {
+	t.query(t.tree.root, lo, func(start Item) bool {
+		return start.Less(hi)
+	}, fn)
+}
+
+// Stab calls the fn for every interval containing the point in ascending order
+// until the fn returns false.
+func (t *IntervalTree) Stab(point Item, fn func(iv Interval) bool) {
+	t.query(t.tree.root, point, func(start Item) bool {
+		return !point.Less(start)
+	}, fn)
+}
+
+// query calls the fn for the intervals of the subtree ending after the lo
+// whose starts are accepted by the before func, skipping the subtrees whose
+// max ends are not after the lo. It returns false if the fn stopped.
+func (t *IntervalTree) query(n *Node, lo Item, before func(start Item) bool, fn func(iv Interval) bool) bool {
+	if n == nil || !lo.Less(n.aggregate(t.tree.agg).(Item)) {
+		return true
+	}
+	for i, item := range n.items {
+		if len(n.children) > 0 && !t.query(n.children[i], lo, before, fn) {
+			return false
+		}
+		iv := item.(Interval)
+		if !before(iv.Start()) {
+			return true
+		}
+		if lo.Less(iv.End()) && !fn(iv) {
+			return false
+		}
+	}
+	if len(n.children) > 0 {
+		return t.query(n.children[len(n.items)], lo, before, fn)
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+type span struct {
+	lo, hi int
+}
+
+func (a span) Less(b Item) bool {
+	if a.lo == b.(span).lo {
+		return a.hi < b.(span).hi
+	}
+	return a.lo < b.(span).lo
+}
+
+func (a span) Start() Item { return Int(a.lo) }
+
+func (a span) End() Item { return Int(a.hi) }
+
+func TestIntervalTree(t *testing.T) {
+	tree := NewInterval(2)
+	tree.Stab(Int(0), func(iv Interval) bool {
+		t.Error(iv)
+		return true
+	})
+	var spans []span
+	for i := 0; i < 200; i++ {
+		s := span{lo: i * 7 % 100, hi: i*7%100 + i%13 + 1}
+		spans = append(spans, s)
+		tree.Insert(s)
+	}
+	check := func() {
+		for lo := -5; lo < 120; lo += 3 {
+			for hi := lo + 1; hi < 125; hi += 7 {
+				want := 0
+				for _, s := range spans {
+					if s.lo < hi && s.hi > lo {
+						want++
+					}
+				}
+				got := 0
+				var last Interval
+				tree.Overlaps(Int(lo), Int(hi), func(iv Interval) bool {
+					if last != nil && !last.Less(iv) {
+						t.Error(last, iv)
+					}
+					last = iv
+					got++
+					return true
+				})
+				if got != want {
+					t.Error(lo, hi, got, want)
+				}
+			}
+			want := 0
+			for _, s := range spans {
+				if s.lo <= lo && lo < s.hi {
+					want++
+				}
+			}
+			got := 0
+			tree.Stab(Int(lo), func(iv Interval) bool {
+				got++
+				return true
+			})
+			if got != want {
+				t.Error(lo, got, want)
+			}
+		}
+	}
+	check()
+	for i := 0; i < len(spans); i += 2 {
+		if !tree.Delete(spans[i]) {
+			t.Error(spans[i])
+		}
+	}
+	if tree.Delete(spans[0]) {
+		t.Error("")
+	}
+	var rest []span
+	for i := 1; i < len(spans); i += 2 {
+		rest = append(rest, spans[i])
+	}
+	spans = rest
+	check()
+	if tree.Length() != len(spans) {
+		t.Error(tree.Length())
+	}
+	count := 0
+	tree.Overlaps(Int(0), Int(100), func(iv Interval) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Error(count)
+	}
+}
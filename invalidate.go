@@ -0,0 +1,91 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sort"
+)
+
+type listener struct {
+	lo, hi Item
+	fn     func()
+}
+
+// listeners is a table of listeners ordered by the lower bounds of the ranges,
+// where maxHi[i] is the max upper bound of the first i+1 listeners.
+type listeners struct {
+	list  []listener
+	maxHi []Item
+}
+
+// OnRangeInvalidate registers the fn to be called after any item in the range
+// [lo, hi) of the B-tree is inserted, replaced or deleted.
+func (t *Tree) OnRangeInvalidate(lo, hi Item, fn func()) {
+	if lo == nil || hi == nil {
+		panic("nil range bound")
+	}
+	if t.listeners == nil {
+		t.listeners = &listeners{}
+	}
+	t.listeners.register(listener{lo: lo, hi: hi, fn: fn})
+}
+
+func (t *Tree) invalidate(item Item) {
+	if t.listeners != nil {
+		t.listeners.notify(item)
+	}
+}
+
+func (t *Tree) invalidateAll() {
+	t.invalidateNode(t.root)
+}
+
+// invalidateNode calls every listener whose range holds any item of the subtree
+// rooted at the n.
+func (t *Tree) invalidateNode(n *Node) {
+	for _, fn := range t.listenersOf(n) {
+		fn()
+	}
+}
+
+// listenersOf returns the fns of the listeners whose ranges hold any item of
+// the subtree rooted at the n.
+func (t *Tree) listenersOf(n *Node) (fns []func()) {
+	if t.listeners == nil || n == nil {
+		return nil
+	}
+	for _, l := range t.listeners.list {
+		if n.rank(l.hi) > n.rank(l.lo) {
+			fns = append(fns, l.fn)
+		}
+	}
+	return
+}
+
+func (l *listeners) register(r listener) {
+	i := sort.Search(len(l.list), func(i int) bool {
+		return r.lo.Less(l.list[i].lo)
+	})
+	l.list = append(l.list, listener{})
+	copy(l.list[i+1:], l.list[i:])
+	l.list[i] = r
+	l.maxHi = append(l.maxHi, nil)
+	for ; i < len(l.list); i++ {
+		l.maxHi[i] = l.list[i].hi
+		if i > 0 && l.maxHi[i].Less(l.maxHi[i-1]) {
+			l.maxHi[i] = l.maxHi[i-1]
+		}
+	}
+}
+
+func (l *listeners) notify(item Item) {
+	i := sort.Search(len(l.list), func(i int) bool {
+		return item.Less(l.list[i].lo)
+	})
+	for i--; i >= 0 && item.Less(l.maxHi[i]); i-- {
+		if item.Less(l.list[i].hi) {
+			l.list[i].fn()
+		}
+	}
+}
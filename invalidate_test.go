@@ -0,0 +1,48 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestOnRangeInvalidate(t *testing.T) {
+	tree := New(2)
+	counts := make([]int, 4)
+	tree.OnRangeInvalidate(Int(10), Int(20), func() { counts[0]++ })
+	tree.OnRangeInvalidate(Int(0), Int(100), func() { counts[1]++ })
+	tree.OnRangeInvalidate(Int(15), Int(16), func() { counts[2]++ })
+	tree.OnRangeInvalidate(Int(50), Int(60), func() { counts[3]++ })
+	check := func(a, b, c, d int) {
+		if counts[0] != a || counts[1] != b || counts[2] != c || counts[3] != d {
+			t.Error(counts, a, b, c, d)
+		}
+	}
+	tree.Insert(Int(15))
+	check(1, 1, 1, 0)
+	tree.Insert(Int(15))
+	check(2, 2, 2, 0)
+	tree.Insert(Int(20))
+	check(2, 3, 2, 0)
+	tree.Insert(Int(100))
+	check(2, 3, 2, 0)
+	tree.Insert(Int(55))
+	check(2, 4, 2, 1)
+	tree.Delete(Int(16))
+	check(2, 4, 2, 1)
+	tree.Delete(Int(15))
+	check(3, 5, 3, 1)
+	tree.DeleteAndNext(Int(55))
+	check(3, 6, 3, 2)
+	tree.Clear()
+	check(3, 7, 3, 2)
+	tree.Clear()
+	check(3, 7, 3, 2)
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	tree.OnRangeInvalidate(nil, Int(0), func() {})
+}
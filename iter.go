@@ -0,0 +1,97 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.23
+// +build go1.23
+
+package btree
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the items of the B-tree in ascending order.
+func (t *Tree) All() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		t.root.ascend(yield)
+	}
+}
+
+// Backward returns an iterator over the items of the B-tree in descending order.
+func (t *Tree) Backward() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		t.root.descendRange(nil, nil, yield)
+	}
+}
+
+// RangeOption represents an option of the bounds of Range.
+type RangeOption func(*rangeBounds)
+
+type rangeBounds struct {
+	exclusiveLo bool
+	inclusiveHi bool
+}
+
+// ExclusiveLo excludes the lower bound from the range.
+func ExclusiveLo() RangeOption {
+	return func(b *rangeBounds) {
+		b.exclusiveLo = true
+	}
+}
+
+// InclusiveHi includes the upper bound in the range.
+func InclusiveHi() RangeOption {
+	return func(b *rangeBounds) {
+		b.inclusiveHi = true
+	}
+}
+
+// Range returns an iterator over the items in the range [lo, hi) of the B-tree
+// in ascending order. The options change the inclusiveness of the bounds. A nil
+// bound leaves the range unbounded on that side.
+func (t *Tree) Range(lo, hi Item, opts ...RangeOption) iter.Seq[Item] {
+	var b rangeBounds
+	for _, opt := range opts {
+		opt(&b)
+	}
+	return func(yield func(Item) bool) {
+		t.root.ascendRange(lo, nil, func(item Item) bool {
+			if b.exclusiveLo && lo != nil && !lo.Less(item) {
+				return true
+			}
+			if hi != nil && (b.inclusiveHi && hi.Less(item) || !b.inclusiveHi && !item.Less(hi)) {
+				return false
+			}
+			return yield(item)
+		})
+	}
+}
+
+// IntersectStream walks the sorted seq and the B-tree together and calls the
+// fn with the stored item equal to every item of the seq, until the fn returns
+// false. The cursor steps through nearby items and seeks from the root after
+// a few steps, so both dense and sparse streams are joined efficiently.
+func (t *Tree) IntersectStream(seq iter.Seq[Item], fn func(item Item) bool) {
+	const steps = 4
+	var it *Iterator
+	for item := range seq {
+		for i := 0; it != nil && it.Item().Less(item); i++ {
+			if i == steps {
+				it = t.root.iterator(item, false)
+				break
+			}
+			it = it.Next()
+		}
+		if it == nil {
+			if t.root == nil {
+				return
+			}
+			if it = t.root.iterator(item, false); it == nil {
+				return
+			}
+		}
+		if !item.Less(it.Item()) && !fn(it.Item()) {
+			return
+		}
+	}
+}
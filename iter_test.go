@@ -0,0 +1,140 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.23
+// +build go1.23
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestAllBackward(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	next := 0
+	for item := range tree.All() {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+		if next == 50 {
+			break
+		}
+	}
+	if next != 50 {
+		t.Error(next)
+	}
+	next = 99
+	for item := range tree.Backward() {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next--
+	}
+	if next != -1 {
+		t.Error(next)
+	}
+	for item := range New(2).All() {
+		t.Error(item)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	collect := func(seq func(func(Item) bool)) (items []int) {
+		for item := range seq {
+			items = append(items, int(item.(Int)))
+		}
+		return
+	}
+	check := func(items []int, first, last int) {
+		if len(items) != last-first+1 || items[0] != first || items[len(items)-1] != last {
+			t.Error(items, first, last)
+		}
+	}
+	check(collect(tree.Range(Int(10), Int(20))), 10, 19)
+	check(collect(tree.Range(Int(10), Int(20), InclusiveHi())), 10, 20)
+	check(collect(tree.Range(Int(10), Int(20), ExclusiveLo())), 11, 19)
+	check(collect(tree.Range(Int(10), Int(20), ExclusiveLo(), InclusiveHi())), 11, 20)
+	if items := collect(tree.Range(Int(20), Int(10))); len(items) != 0 {
+		t.Error(items)
+	}
+	check(collect(tree.Range(nil, Int(20))), 0, 19)
+	check(collect(tree.Range(nil, Int(20), ExclusiveLo(), InclusiveHi())), 0, 20)
+	check(collect(tree.Range(Int(90), nil, ExclusiveLo())), 91, 99)
+	check(collect(tree.Range(Int(90), nil, InclusiveHi())), 90, 99)
+	check(collect(tree.Range(nil, nil)), 0, 99)
+	for item := range tree.Range(Int(10), Int(20)) {
+		if item.(Int) != 10 {
+			t.Error(item)
+		}
+		break
+	}
+}
+
+func TestIntersectStream(t *testing.T) {
+	tree := New(2)
+	stream := func(items ...int) func(yield func(Item) bool) {
+		return func(yield func(Item) bool) {
+			for _, i := range items {
+				if !yield(Int(i)) {
+					return
+				}
+			}
+		}
+	}
+	tree.IntersectStream(stream(1, 2, 3), func(item Item) bool {
+		t.Error(item)
+		return true
+	})
+	for i := 0; i < 200; i += 2 {
+		tree.Insert(&pointer{i})
+	}
+	pointers := func(items ...int) func(yield func(Item) bool) {
+		return func(yield func(Item) bool) {
+			for _, i := range items {
+				if !yield(&pointer{i}) {
+					return
+				}
+			}
+		}
+	}
+	var dense []int
+	for i := -5; i < 300; i++ {
+		dense = append(dense, i)
+	}
+	var got []int
+	tree.IntersectStream(pointers(dense...), func(item Item) bool {
+		if tree.Search(item) != item {
+			t.Error(item)
+		}
+		got = append(got, item.(*pointer).value)
+		return true
+	})
+	if len(got) != 100 || got[0] != 0 || got[99] != 198 {
+		t.Error(got)
+	}
+	got = got[:0]
+	tree.IntersectStream(pointers(3, 4, 100, 101, 150, 151, 152, 152, 500), func(item Item) bool {
+		got = append(got, item.(*pointer).value)
+		return true
+	})
+	if len(got) != 5 || got[0] != 4 || got[1] != 100 || got[2] != 150 || got[4] != 152 {
+		t.Error(got)
+	}
+	count := 0
+	tree.IntersectStream(pointers(dense...), func(item Item) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Error(count)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"encoding/json"
+)
+
+type jsonTree struct {
+	Degree int               `json:"degree"`
+	Items  []json.RawMessage `json:"items"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. It returns an object
+// holding the degree and the array of the items in ascending order. The items
+// are encoded by the codec set by SetCodec, which must encode them as JSON
+// values, so that UnmarshalJSON can decode them. It returns ErrCodec if no
+// codec is set.
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	if t.codec == nil {
+		return nil, ErrCodec
+	}
+	doc := jsonTree{Degree: t.degree, Items: make([]json.RawMessage, 0, t.length)}
+	var err error
+	t.Ascend(func(item Item) bool {
+		var data []byte
+		data, err = t.codec.Marshal(item)
+		doc.Items = append(doc.Items, data)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// items and the degree of the B-tree with the ones in the data written by
+// MarshalJSON, decoding the items by the codec set by SetCodec. It returns
+// ErrCodec if no codec is set. The items must be in ascending order, or
+// ErrOrder is returned.
+func (t *Tree) UnmarshalJSON(data []byte) error {
+	if t.codec == nil {
+		return ErrCodec
+	}
+	var doc jsonTree
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := ValidateDegree(doc.Degree); err != nil {
+		return err
+	}
+	sorted := make([]Item, 0, len(doc.Items))
+	for _, raw := range doc.Items {
+		item, err := t.codec.Unmarshal(raw)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			panic("nil item being inserted to tree")
+		}
+		if n := len(sorted); n > 0 && !sorted[n-1].Less(item) {
+			return ErrOrder
+		}
+		sorted = append(sorted, item)
+	}
+	t.load(LoadSorted(doc.Degree, sorted))
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 5; i++ {
+		tree.Insert(Int(4 - i))
+	}
+	if _, err := tree.MarshalJSON(); err != ErrCodec {
+		t.Error(err)
+	}
+	tree.SetCodec(intCodec{})
+	data, err := json.Marshal(tree)
+	if err != nil || string(data) != `{"degree":3,"items":[0,1,2,3,4]}` {
+		t.Error(string(data), err)
+	}
+	if err := New(2).UnmarshalJSON(data); err != ErrCodec {
+		t.Error(err)
+	}
+	var state struct {
+		Name  string `json:"name"`
+		Index *Tree  `json:"index"`
+	}
+	state.Index = New(2)
+	state.Index.SetCodec(intCodec{})
+	state.Index.Insert(Int(9))
+	if err := json.Unmarshal([]byte(`{"name":"a","index":`+string(data)+`}`), &state); err != nil {
+		t.Error(err)
+	}
+	testTraversal(state.Index, t)
+	if state.Index.degree != 3 || state.Index.Length() != 5 || state.Index.MaxItem().(Int) != 4 {
+		t.Error(state.Index.Length())
+	}
+	if out, err := json.Marshal(state); err != nil || string(out) != `{"name":"a","index":`+string(data)+`}` {
+		t.Error(string(out), err)
+	}
+	if err := tree.UnmarshalJSON([]byte(`{"degree":2,"items":[1,1]}`)); err != ErrOrder || tree.Length() != 5 {
+		t.Error(err)
+	}
+	if err := tree.UnmarshalJSON([]byte(`{"degree":1,"items":[]}`)); err != ErrDegree {
+		t.Error(err)
+	}
+	if err := tree.UnmarshalJSON([]byte(`{"degree":2,"items":["a"]}`)); err == nil {
+		t.Error("")
+	}
+	if err := tree.UnmarshalJSON([]byte(`[`)); err == nil {
+		t.Error("")
+	}
+	if err := tree.UnmarshalJSON([]byte(`{"degree":2,"items":[]}`)); err != nil || tree.Length() != 0 {
+		t.Error(err)
+	}
+	tree.Insert(Int(1))
+	tree.SetCodec(errorCodec{})
+	if _, err := tree.MarshalJSON(); err == nil {
+		t.Error("")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// keyCheck records the keys of the stored items when they were inserted.
+type keyCheck struct {
+	key  func(item Item) interface{}
+	keys map[Item]interface{}
+}
+
+// EnableKeyCheck enables a debug check that the keys of the stored items do not
+// change after they are inserted, which catches mutating the key fields of an
+// item through a retained pointer. The key func derives the comparable key the
+// item is ordered by. The key of every item is recorded on insert and compared
+// again when the item is returned by Search or Get, and by CheckKeys.
+//
+// Only the items of comparable types, such as pointers, are checked. The check
+// is not inherited by clones.
+func (t *Tree) EnableKeyCheck(key func(item Item) interface{}) {
+	t.keys = &keyCheck{key: key, keys: make(map[Item]interface{})}
+	t.Ascend(func(item Item) bool {
+		t.keys.record(item)
+		return true
+	})
+}
+
+// CheckKeys returns an error if the key of any stored item has changed since
+// it was inserted.
+func (t *Tree) CheckKeys() (err error) {
+	if t.keys == nil {
+		panic("key check not enabled")
+	}
+	t.Ascend(func(item Item) bool {
+		err = t.keys.check(item)
+		return err == nil
+	})
+	return
+}
+
+func (c *keyCheck) record(item Item) {
+	if reflect.TypeOf(item).Comparable() {
+		c.keys[item] = c.key(item)
+	}
+}
+
+func (c *keyCheck) forget(item Item) {
+	if reflect.TypeOf(item).Comparable() {
+		delete(c.keys, item)
+	}
+}
+
+func (c *keyCheck) check(item Item) error {
+	if !reflect.TypeOf(item).Comparable() {
+		return nil
+	}
+	if key, ok := c.keys[item]; ok {
+		if current := c.key(item); current != key {
+			return fmt.Errorf("item key changed from %v to %v after insert", key, current)
+		}
+	}
+	return nil
+}
+
+// recordKey updates the recorded keys and the generations after the old item
+// is replaced by the item, either of which may be nil.
+func (t *Tree) recordKey(old, item Item) {
+	if t.gens != nil {
+		if item != nil {
+			t.gens.stamp(item)
+		} else {
+			t.gens.forget(old)
+		}
+	}
+	if t.keys == nil {
+		return
+	}
+	if old != nil {
+		t.keys.forget(old)
+	}
+	if item != nil {
+		t.keys.record(item)
+	}
+}
+
+// checkKey panics if the key of the found item has changed since it was inserted.
+func (t *Tree) checkKey(found Item) {
+	if t.keys != nil && found != nil {
+		if err := t.keys.check(found); err != nil {
+			panic(err.Error())
+		}
+	}
+}
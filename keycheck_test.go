@@ -0,0 +1,65 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestKeyCheck(t *testing.T) {
+	tree := New(2)
+	kept := &pointer{5}
+	tree.Insert(kept)
+	tree.EnableKeyCheck(func(item Item) interface{} {
+		return item.(*pointer).value
+	})
+	for i := 0; i < 50; i++ {
+		if i != 5 {
+			tree.Insert(&pointer{i})
+		}
+	}
+	if err := tree.CheckKeys(); err != nil {
+		t.Error(err)
+	}
+	replaced := &pointer{7}
+	tree.ReplaceOrInsert(replaced)
+	tree.InsertBatch([]Item{&pointer{8}, &pointer{100}})
+	tree.Update(&pointer{9}, func(old Item, exists bool) (Item, bool) {
+		return &pointer{9}, true
+	})
+	removed, _ := tree.DeleteItem(&pointer{10})
+	removed.(*pointer).value = 1000
+	tree.DeleteRange(&pointer{20}, &pointer{40})
+	if len(tree.keys.keys) != tree.Length() || tree.CheckKeys() != nil {
+		t.Error(len(tree.keys.keys), tree.Length())
+	}
+	kept.value = 4
+	if tree.CheckKeys() == nil {
+		t.Error("")
+	}
+	kept.value = 5
+	if item, ok := tree.Get(&pointer{5}); !ok || item != kept || tree.Search(&pointer{5}) != kept {
+		t.Error(item)
+	}
+	tree.Clear()
+	if len(tree.keys.keys) != 0 {
+		t.Error(len(tree.keys.keys))
+	}
+	tree.Insert(kept)
+	kept.value = 6
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				t.Error("")
+			}
+		}()
+		tree.Get(&pointer{6})
+	}()
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	New(2).CheckKeys()
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// Codec represents an encoding of items.
+type Codec interface {
+	// Marshal returns the encoding of the item.
+	Marshal(item Item) ([]byte, error)
+	// Unmarshal returns the item decoded from the data.
+	Unmarshal(data []byte) (Item, error)
+}
+
+// Loader builds a B-tree from unsorted items that may not fit in memory.
+// The items are sorted in chunks spilled to temporary files, which are
+// merged into the B-tree by Load. Of the equal items the last added one is kept.
+type Loader struct {
+	degree int
+	codec  Codec
+	chunk  int
+	dir    string
+	buf    []Item
+	files  []*os.File
+}
+
+// NewLoader returns a new loader with the given degree, codec, the max number of
+// items per chunk and the directory of the temporary files.
+func NewLoader(degree int, codec Codec, chunk int, dir string) *Loader {
+	if ValidateDegree(degree) != nil {
+		panic("bad degree")
+	}
+	if chunk <= 0 {
+		panic("bad chunk size")
+	}
+	return &Loader{degree: degree, codec: codec, chunk: chunk, dir: dir}
+}
+
+// Add adds the item to the loader.
+func (l *Loader) Add(item Item) error {
+	if item == nil {
+		panic("nil item being added to loader")
+	}
+	l.buf = append(l.buf, item)
+	if len(l.buf) >= l.chunk {
+		return l.spill()
+	}
+	return nil
+}
+
+// Load merges the added items into a new B-tree and removes the temporary
+// files. The merged items are streamed into the B-tree in batches of the chunk
+// size, so no more than a chunk of items is held outside the B-tree.
+func (l *Loader) Load() (*Tree, error) {
+	defer l.Close()
+	s := &sortedLoader{tree: New(l.degree), batch: make([]Item, 0, l.chunk+1)}
+	if len(l.files) == 0 {
+		l.sort()
+		for _, item := range l.buf {
+			s.add(item)
+		}
+		l.buf = nil
+		return s.load(), nil
+	}
+	if len(l.buf) > 0 {
+		if err := l.spill(); err != nil {
+			return nil, err
+		}
+	}
+	h := make(chunkHeap, 0, len(l.files))
+	for i, f := range l.files {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		c := &chunkReader{index: i, r: bufio.NewReader(f), codec: l.codec}
+		if ok, err := c.next(); err != nil {
+			return nil, err
+		} else if ok {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+	for len(h) > 0 {
+		c := h[0]
+		s.add(c.item)
+		if ok, err := c.next(); err != nil {
+			return nil, err
+		} else if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return s.load(), nil
+}
+
+// Close removes the temporary files of the loader.
+func (l *Loader) Close() error {
+	var err error
+	for _, f := range l.files {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+		if e := os.Remove(f.Name()); e != nil && err == nil {
+			err = e
+		}
+	}
+	l.files = nil
+	l.buf = nil
+	return err
+}
+
+func (l *Loader) sort() {
+	sort.SliceStable(l.buf, func(i, j int) bool {
+		return l.buf[i].Less(l.buf[j])
+	})
+}
+
+func (l *Loader) spill() error {
+	l.sort()
+	f, err := ioutil.TempFile(l.dir, "btree-")
+	if err != nil {
+		return err
+	}
+	l.files = append(l.files, f)
+	w := bufio.NewWriter(f)
+	var size [binary.MaxVarintLen64]byte
+	for _, item := range l.buf {
+		data, err := l.codec.Marshal(item)
+		if err != nil {
+			return err
+		}
+		n := binary.PutUvarint(size[:], uint64(len(data)))
+		if _, err = w.Write(size[:n]); err != nil {
+			return err
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+	for i := range l.buf {
+		l.buf[i] = nil
+	}
+	l.buf = l.buf[:0]
+	return w.Flush()
+}
+
+// sortedLoader builds a B-tree from the items added in ascending order, of the
+// equal items keeping the last one. Every full batch is packed by LoadSorted
+// and joined onto the right spine of the B-tree with its first item as the
+// separator. The last item is held back in the batch until a greater one is
+// added, so an equal item can still replace it.
+type sortedLoader struct {
+	tree  *Tree
+	batch []Item
+}
+
+func (s *sortedLoader) add(item Item) {
+	if n := len(s.batch); n > 0 && !s.batch[n-1].Less(item) {
+		s.batch[n-1] = item
+		return
+	}
+	if len(s.batch) == cap(s.batch) {
+		last := s.batch[len(s.batch)-1]
+		s.flush(s.batch[:len(s.batch)-1])
+		s.batch = append(s.batch[:0], last)
+	}
+	s.batch = append(s.batch, item)
+}
+
+// flush joins the items onto the B-tree.
+func (s *sortedLoader) flush(items []Item) {
+	if len(items) == 0 {
+		return
+	}
+	t := s.tree
+	if t.root == nil {
+		t.root = LoadSorted(t.degree, items).root
+	} else {
+		t.root = t.join(t.root, items[0], LoadSorted(t.degree, items[1:]).root)
+	}
+	t.length += len(items)
+}
+
+// load flushes the held items and returns the B-tree.
+func (s *sortedLoader) load() *Tree {
+	s.flush(s.batch)
+	s.batch = nil
+	s.tree.setExtremes()
+	return s.tree
+}
+
+type chunkReader struct {
+	index int
+	r     *bufio.Reader
+	codec Codec
+	item  Item
+}
+
+func (c *chunkReader) next() (bool, error) {
+	size, err := binary.ReadUvarint(c.r)
+	if err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(c.r, data); err != nil {
+		return false, err
+	}
+	c.item, err = c.codec.Unmarshal(data)
+	return err == nil, err
+}
+
+// chunkHeap orders the chunk readers by their items, and the equal items
+// by the order the chunks were spilled.
+type chunkHeap []*chunkReader
+
+func (h chunkHeap) Len() int { return len(h) }
+
+func (h chunkHeap) Less(i, j int) bool {
+	if h[i].item.Less(h[j].item) {
+		return true
+	} else if h[j].item.Less(h[i].item) {
+		return false
+	}
+	return h[i].index < h[j].index
+}
+
+func (h chunkHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *chunkHeap) Push(x interface{}) { *h = append(*h, x.(*chunkReader)) }
+
+func (h *chunkHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+)
+
+type intCodec struct{}
+
+func (intCodec) Marshal(item Item) ([]byte, error) {
+	return []byte(strconv.Itoa(int(item.(Int)))), nil
+}
+
+func (intCodec) Unmarshal(data []byte) (Item, error) {
+	i, err := strconv.Atoi(string(data))
+	return Int(i), err
+}
+
+type errorCodec struct{}
+
+func (errorCodec) Marshal(item Item) ([]byte, error) {
+	return nil, errors.New("marshal")
+}
+
+func (errorCodec) Unmarshal(data []byte) (Item, error) {
+	return nil, errors.New("unmarshal")
+}
+
+func TestLoader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, chunk := range []int{1, 7, 100, 10000} {
+		l := NewLoader(3, intCodec{}, chunk, dir)
+		n := 1000
+		for _, i := range rand.Perm(n) {
+			if err := l.Add(Int(i)); err != nil {
+				t.Fatal(err)
+			}
+			if err := l.Add(Int(i % 10)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tree, err := l.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		testTraversal(tree, t)
+		if tree.Length() != n {
+			t.Error(chunk, tree.Length())
+		}
+		files, _ := ioutil.ReadDir(dir)
+		if len(files) != 0 {
+			t.Error(len(files))
+		}
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l := NewLoader(3, errorCodec{}, 1, dir)
+	if err := l.Add(Int(0)); err == nil {
+		t.Error("")
+	}
+	l.Close()
+	l = NewLoader(3, intCodec{}, 1, dir)
+	l.Add(Int(0))
+	l.codec = errorCodec{}
+	if _, err := l.Load(); err == nil {
+		t.Error("")
+	}
+	l = NewLoader(3, intCodec{}, 1, dir+"/none")
+	if err := l.Add(Int(0)); err == nil {
+		t.Error("")
+	}
+	for _, fn := range []func(){
+		func() { NewLoader(1, intCodec{}, 1, dir) },
+		func() { NewLoader(2, intCodec{}, 0, dir) },
+		func() { NewLoader(2, intCodec{}, 1, dir).Add(nil) },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestSortedLoader(t *testing.T) {
+	for _, size := range []int{1, 2, 5, 64} {
+		for _, d := range []int{2, 3, 7} {
+			s := &sortedLoader{tree: New(d), batch: make([]Item, 0, size+1)}
+			for i := 0; i < 1000; i++ {
+				s.add(tagged{key: i / 3, tag: strconv.Itoa(i % 3)})
+			}
+			tree := s.load()
+			testTraversal(tree, t)
+			if tree.Length() != 334 {
+				t.Error(size, d, tree.Length())
+			}
+			i := 0
+			tree.Ascend(func(item Item) bool {
+				if item.(tagged).key != i || i < 333 && item.(tagged).tag != "2" {
+					t.Error(size, d, item)
+				}
+				i++
+				return true
+			})
+		}
+	}
+	if tree := (&sortedLoader{tree: New(2), batch: make([]Item, 0, 2)}).load(); tree.Length() != 0 || tree.Root() != nil {
+		t.Error("")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package btree
+
+import (
+	"cmp"
+)
+
+// Map represents an ordered map from keys to values backed by a B-tree.
+type Map[K cmp.Ordered, V any] struct {
+	tree *Tree
+}
+
+type mapEntry[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+}
+
+func (a *mapEntry[K, V]) Less(b Item) bool {
+	return cmp.Less(a.key, b.(*mapEntry[K, V]).key)
+}
+
+// NewMap returns a new Map with the given degree.
+func NewMap[K cmp.Ordered, V any](degree int) *Map[K, V] {
+	return &Map[K, V]{tree: New(degree)}
+}
+
+// Len returns the number of keys in the map.
+func (m *Map[K, V]) Len() int {
+	return m.tree.Length()
+}
+
+// Set sets the value of the key and returns the replaced value.
+func (m *Map[K, V]) Set(key K, value V) (old V, replaced bool) {
+	actual, loaded := m.tree.GetOrInsert(&mapEntry[K, V]{key: key, value: value})
+	if loaded {
+		e := actual.(*mapEntry[K, V])
+		old, e.value = e.value, value
+	}
+	return old, loaded
+}
+
+// Get returns the value of the key and whether it was found.
+func (m *Map[K, V]) Get(key K) (value V, ok bool) {
+	if found := m.tree.Search(&mapEntry[K, V]{key: key}); found != nil {
+		return found.(*mapEntry[K, V]).value, true
+	}
+	return
+}
+
+// Delete deletes the key and returns its value.
+func (m *Map[K, V]) Delete(key K) (value V, ok bool) {
+	if removed, ok := m.tree.DeleteItem(&mapEntry[K, V]{key: key}); ok {
+		return removed.(*mapEntry[K, V]).value, true
+	}
+	return
+}
+
+// Min returns the min key with its value, and false if the map is empty.
+func (m *Map[K, V]) Min() (key K, value V, ok bool) {
+	if item := m.tree.MinItem(); item != nil {
+		e := item.(*mapEntry[K, V])
+		return e.key, e.value, true
+	}
+	return
+}
+
+// Max returns the max key with its value, and false if the map is empty.
+func (m *Map[K, V]) Max() (key K, value V, ok bool) {
+	if item := m.tree.MaxItem(); item != nil {
+		e := item.(*mapEntry[K, V])
+		return e.key, e.value, true
+	}
+	return
+}
+
+// Ascend calls the fn for every key with its value in ascending order until
+// the fn returns false.
+func (m *Map[K, V]) Ascend(fn func(key K, value V) bool) {
+	m.tree.Ascend(func(item Item) bool {
+		e := item.(*mapEntry[K, V])
+		return fn(e.key, e.value)
+	})
+}
+
+// AscendRange calls the fn for every key in the range [greaterOrEqual, lessThan)
+// with its value in ascending order until the fn returns false.
+func (m *Map[K, V]) AscendRange(greaterOrEqual, lessThan K, fn func(key K, value V) bool) {
+	m.tree.AscendRange(&mapEntry[K, V]{key: greaterOrEqual}, &mapEntry[K, V]{key: lessThan}, func(item Item) bool {
+		e := item.(*mapEntry[K, V])
+		return fn(e.key, e.value)
+	})
+}
+
+// Descend calls the fn for every key with its value in descending order until
+// the fn returns false.
+func (m *Map[K, V]) Descend(fn func(key K, value V) bool) {
+	m.tree.Descend(func(item Item) bool {
+		e := item.(*mapEntry[K, V])
+		return fn(e.key, e.value)
+	})
+}
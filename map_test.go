@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package btree
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	m := NewMap[string, int](2)
+	if _, _, ok := m.Min(); ok {
+		t.Error("")
+	}
+	if _, _, ok := m.Max(); ok {
+		t.Error("")
+	}
+	for i := 0; i < 100; i++ {
+		if _, replaced := m.Set(strconv.Itoa(i), i); replaced {
+			t.Error(i)
+		}
+	}
+	if old, replaced := m.Set("7", 700); !replaced || old != 7 {
+		t.Error(old)
+	}
+	if v, ok := m.Get("7"); !ok || v != 700 || m.Len() != 100 {
+		t.Error(v)
+	}
+	if _, ok := m.Get("x"); ok {
+		t.Error("")
+	}
+	if v, ok := m.Delete("7"); !ok || v != 700 {
+		t.Error(v)
+	}
+	if _, ok := m.Delete("7"); ok || m.Len() != 99 {
+		t.Error("")
+	}
+	if k, v, ok := m.Min(); !ok || k != "0" || v != 0 {
+		t.Error(k, v)
+	}
+	if k, v, ok := m.Max(); !ok || k != "99" || v != 99 {
+		t.Error(k, v)
+	}
+	last := ""
+	count := 0
+	m.Ascend(func(k string, v int) bool {
+		if k <= last || strconv.Itoa(v) != k {
+			t.Error(k, v)
+		}
+		last = k
+		count++
+		return true
+	})
+	if count != 99 {
+		t.Error(count)
+	}
+	var keys []string
+	m.AscendRange("1", "11", func(k string, v int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if len(keys) != 2 || keys[0] != "1" || keys[1] != "10" {
+		t.Error(keys)
+	}
+	m.Descend(func(k string, v int) bool {
+		if k != "99" {
+			t.Error(k)
+		}
+		return false
+	})
+}
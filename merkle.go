@@ -0,0 +1,197 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// EnableMerkle enables the content hashes of the nodes with the hash of the items.
+// The content hash of a subtree is the sum of the SHA-256 digests of the hashes
+// of its items modulo 2^256, so it depends only on the items and not on how they
+// are split into nodes. The hashes are computed lazily and a mutation only
+// invalidates the hashes of the nodes on its path. The hashes are cached under
+// the hash enabled last, so the clones sharing the nodes may enable other hashes.
+func (t *Tree) EnableMerkle(hash func(item Item) []byte) {
+	t.hash = &merkleHash{hash: hash}
+}
+
+// MerkleRoot returns the content hash of the root node of the B-tree. The
+// B-trees holding the same items have the same content hash regardless of
+// their degrees and the order of the mutations that built them.
+func (t *Tree) MerkleRoot() []byte {
+	if t.hash == nil {
+		panic("merkle not enabled")
+	}
+	if t.root == nil {
+		return nil
+	}
+	return t.root.merkle(t.hash)
+}
+
+// DiffByHash returns the items whose keys are absent from either B-tree or whose
+// hashes differ, in ascending order. The B-trees are compared by the content
+// hashes of key ranges rather than of nodes, so their shapes may differ. A range
+// with differing hashes is split at the median key of the larger side until it
+// holds no more items than a node, and then the items are merged.
+func (t *Tree) DiffByHash(other *Tree) []Item {
+	if t.hash == nil || other.hash == nil {
+		panic("merkle not enabled")
+	}
+	d := &merkleDiff{a: t, b: other}
+	d.diff(nil, nil)
+	return d.items
+}
+
+// merkleHash is the hash of the items enabled by EnableMerkle.
+type merkleHash struct {
+	hash func(item Item) []byte
+}
+
+// hashCache is the content hash of a subtree cached with the hash that
+// computed it.
+type hashCache struct {
+	merkle *merkleHash
+	sum    []byte
+}
+
+type merkleDiff struct {
+	a, b  *Tree
+	items []Item
+}
+
+// diff appends the differing items in the range [lo, hi).
+func (d *merkleDiff) diff(lo, hi Item) {
+	if bytes.Equal(d.a.rangeHash(lo, hi), d.b.rangeHash(lo, hi)) {
+		return
+	}
+	larger, count := d.a, d.a.CountRange(lo, hi)
+	if n := d.b.CountRange(lo, hi); n > count {
+		larger, count = d.b, n
+	}
+	if count <= larger.MaxItems() {
+		d.merge(lo, hi)
+		return
+	}
+	start := 0
+	if lo != nil {
+		start = larger.Rank(lo)
+	}
+	median, _ := larger.At(start + count/2)
+	d.diff(lo, median)
+	d.diff(median, hi)
+}
+
+func (d *merkleDiff) merge(lo, hi Item) {
+	var left, right []Item
+	d.a.root.ascendRange(lo, hi, func(item Item) bool {
+		left = append(left, item)
+		return true
+	})
+	d.b.root.ascendRange(lo, hi, func(item Item) bool {
+		right = append(right, item)
+		return true
+	})
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		if left[i].Less(right[j]) {
+			d.items = append(d.items, left[i])
+			i++
+		} else if right[j].Less(left[i]) {
+			d.items = append(d.items, right[j])
+			j++
+		} else {
+			if !bytes.Equal(d.a.hash.hash(left[i]), d.b.hash.hash(right[j])) {
+				d.items = append(d.items, left[i])
+			}
+			i++
+			j++
+		}
+	}
+	d.items = append(d.items, left[i:]...)
+	d.items = append(d.items, right[j:]...)
+}
+
+// rangeHash returns the content hash of the items in the range [lo, hi) of the
+// B-tree in O(log n) time. A nil bound leaves the range unbounded on that side.
+func (t *Tree) rangeHash(lo, hi Item) []byte {
+	sum := make([]byte, sha256.Size)
+	t.root.merkleRange(lo, hi, t.hash, sum)
+	return sum
+}
+
+func (n *Node) merkle(m *merkleHash) []byte {
+	if c := n.cachedHash(); c != nil && c.merkle == m {
+		return c.sum
+	}
+	sum := make([]byte, sha256.Size)
+	for _, child := range n.children {
+		addDigest(sum, child.merkle(m))
+	}
+	for _, item := range n.items {
+		digest := sha256.Sum256(m.hash(item))
+		addDigest(sum, digest[:])
+	}
+	n.hash.Store(&hashCache{merkle: m, sum: sum})
+	return sum
+}
+
+// cachedHash returns the content hash cached in the node. The readers of the
+// B-trees sharing the node may cache their hashes concurrently, so the cache
+// is loaded and stored atomically.
+func (n *Node) cachedHash() *hashCache {
+	c, _ := n.hash.Load().(*hashCache)
+	return c
+}
+
+// merkleRange adds the digests of the items in the range [lo, hi) of the
+// subtree to the sum, using the content hashes of the children entirely in the
+// range.
+func (n *Node) merkleRange(lo, hi Item, m *merkleHash, sum []byte) {
+	if n == nil {
+		return
+	}
+	if lo == nil && hi == nil {
+		addDigest(sum, n.merkle(m))
+		return
+	}
+	start, found := 0, false
+	if lo != nil {
+		start, found = n.items.search(lo)
+	}
+	end := len(n.items)
+	if hi != nil {
+		end, _ = n.items.search(hi)
+	}
+	if len(n.children) > 0 {
+		if start == end {
+			n.children[start].merkleRange(lo, hi, m, sum)
+			return
+		}
+		if !found {
+			n.children[start].merkleRange(lo, nil, m, sum)
+		}
+	}
+	for i := start; i < end; i++ {
+		digest := sha256.Sum256(m.hash(n.items[i]))
+		addDigest(sum, digest[:])
+		if len(n.children) > 0 && i+1 < end {
+			addDigest(sum, n.children[i+1].merkle(m))
+		}
+	}
+	if len(n.children) > 0 {
+		n.children[end].merkleRange(nil, hi, m, sum)
+	}
+}
+
+// addDigest adds the big-endian digest to the sum modulo 2^256.
+func addDigest(sum, digest []byte) {
+	carry := 0
+	for i := len(sum) - 1; i >= 0; i-- {
+		carry += int(sum[i]) + int(digest[i])
+		sum[i] = byte(carry)
+		carry >>= 8
+	}
+}
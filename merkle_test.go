@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+type tagged struct {
+	key int
+	tag string
+}
+
+func (a tagged) Less(b Item) bool {
+	return a.key < b.(tagged).key
+}
+
+func hashTagged(item Item) []byte {
+	return []byte(strconv.Itoa(item.(tagged).key) + item.(tagged).tag)
+}
+
+func TestMerkle(t *testing.T) {
+	a := New(3)
+	b := New(3)
+	a.EnableMerkle(hashTagged)
+	b.EnableMerkle(hashTagged)
+	if a.MerkleRoot() != nil {
+		t.Error("")
+	}
+	for i := 0; i < 500; i++ {
+		a.Insert(tagged{key: i})
+		b.Insert(tagged{key: i})
+	}
+	if !bytes.Equal(a.MerkleRoot(), b.MerkleRoot()) {
+		t.Error("")
+	}
+	if diff := a.DiffByHash(b); len(diff) != 0 {
+		t.Error(diff)
+	}
+	b.Insert(tagged{key: 100, tag: "x"})
+	b.Insert(tagged{key: 101, tag: "x"})
+	if bytes.Equal(a.MerkleRoot(), b.MerkleRoot()) {
+		t.Error("")
+	}
+	diff := a.DiffByHash(b)
+	if len(diff) != 2 || diff[0].(tagged).key != 100 || diff[1].(tagged).key != 101 {
+		t.Error(diff)
+	}
+	b.Delete(tagged{key: 300})
+	b.Insert(tagged{key: 1000})
+	diff = a.DiffByHash(b)
+	if len(diff) != 4 || diff[2].(tagged).key != 300 || diff[3].(tagged).key != 1000 {
+		t.Error(diff)
+	}
+	c := New(3)
+	c.EnableMerkle(hashTagged)
+	for i := 499; i >= 0; i-- {
+		c.Insert(tagged{key: i})
+	}
+	if diff := a.DiffByHash(c); len(diff) != 0 || !bytes.Equal(a.MerkleRoot(), c.MerkleRoot()) {
+		t.Error(diff)
+	}
+	sorted := make([]Item, 0, 500)
+	for i := 0; i < 500; i++ {
+		sorted = append(sorted, tagged{key: i})
+	}
+	for _, d := range []int{2, 4, 7} {
+		loaded := LoadSorted(d, sorted)
+		loaded.EnableMerkle(hashTagged)
+		if !bytes.Equal(a.MerkleRoot(), loaded.MerkleRoot()) {
+			t.Error(d)
+		}
+		if diff := a.DiffByHash(loaded); len(diff) != 0 {
+			t.Error(d, diff)
+		}
+	}
+	empty := New(3)
+	empty.EnableMerkle(hashTagged)
+	if diff := a.DiffByHash(empty); len(diff) != 500 {
+		t.Error(len(diff))
+	}
+	root := a.MerkleRoot()
+	a.EnableMerkle(hashTagged)
+	if !bytes.Equal(root, a.MerkleRoot()) {
+		t.Error("")
+	}
+	for _, fn := range []func(){
+		func() { New(2).MerkleRoot() },
+		func() { a.DiffByHash(New(2)) },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestMerkleClone(t *testing.T) {
+	a := New(3)
+	a.EnableMerkle(hashTagged)
+	for i := 0; i < 500; i++ {
+		a.Insert(tagged{key: i})
+	}
+	root := a.MerkleRoot()
+	c := a.Clone()
+	c.EnableMerkle(func(item Item) []byte {
+		return []byte(strconv.Itoa(-item.(tagged).key))
+	})
+	if bytes.Equal(root, c.MerkleRoot()) {
+		t.Error("")
+	}
+	if !bytes.Equal(root, a.MerkleRoot()) {
+		t.Error("")
+	}
+	c.Insert(tagged{key: 1000})
+	if !bytes.Equal(root, a.MerkleRoot()) {
+		t.Error("")
+	}
+}
+
+func TestDiffByHashShapes(t *testing.T) {
+	hashes := 0
+	hash := func(item Item) []byte {
+		hashes++
+		return hashTagged(item)
+	}
+	sorted := make([]Item, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		sorted = append(sorted, tagged{key: i})
+	}
+	a := LoadSorted(2, sorted)
+	b := LoadSorted(7, sorted)
+	a.EnableMerkle(hash)
+	b.EnableMerkle(hash)
+	a.MerkleRoot()
+	b.MerkleRoot()
+	a.Insert(tagged{key: 5000, tag: "x"})
+	b.Delete(tagged{key: 7000})
+	hashes = 0
+	diff := a.DiffByHash(b)
+	if len(diff) != 2 || diff[0].(tagged).key != 5000 || diff[1].(tagged).key != 7000 {
+		t.Error(diff)
+	}
+	if hashes > 2000 {
+		t.Error(hashes)
+	}
+}
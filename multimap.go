@@ -0,0 +1,95 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Multimap represents a B-tree storing the equal items as separate entries,
+// which are kept in insertion order within the run of the equal items.
+type Multimap struct {
+	tree *Tree
+	seq  uint64
+}
+
+type multimapEntry struct {
+	item Item
+	seq  uint64
+}
+
+func (a multimapEntry) Less(b Item) bool {
+	x := b.(multimapEntry)
+	if a.item.Less(x.item) {
+		return true
+	} else if x.item.Less(a.item) {
+		return false
+	}
+	return a.seq < x.seq
+}
+
+// NewMultimap returns a new Multimap with the given degree.
+func NewMultimap(degree int) *Multimap {
+	return &Multimap{tree: New(degree)}
+}
+
+// Length returns the number of entries.
+func (m *Multimap) Length() int {
+	return m.tree.Length()
+}
+
+// bounds returns the range holding all the entries equal to the key.
+func (m *Multimap) bounds(key Item) (lo, hi Item) {
+	return multimapEntry{item: key}, multimapEntry{item: key, seq: ^uint64(0)}
+}
+
+// Insert inserts the item as a new entry after the equal entries.
+func (m *Multimap) Insert(item Item) {
+	if item == nil {
+		panic("nil item being inserted to multimap")
+	}
+	m.seq++
+	m.tree.Insert(multimapEntry{item: item, seq: m.seq})
+}
+
+// SearchAll returns all the items equal to the key in insertion order.
+func (m *Multimap) SearchAll(key Item) (items []Item) {
+	lo, hi := m.bounds(key)
+	m.tree.AscendRange(lo, hi, func(item Item) bool {
+		items = append(items, item.(multimapEntry).item)
+		return true
+	})
+	return
+}
+
+// Count returns the number of the items equal to the key in O(log n) time.
+func (m *Multimap) Count(key Item) int {
+	lo, hi := m.bounds(key)
+	return m.tree.CountRange(lo, hi)
+}
+
+// Delete deletes the first inserted of the items equal to the key and returns it.
+func (m *Multimap) Delete(key Item) (Item, bool) {
+	lo, hi := m.bounds(key)
+	var first Item
+	m.tree.AscendRange(lo, hi, func(item Item) bool {
+		first = item
+		return false
+	})
+	if first == nil {
+		return nil, false
+	}
+	m.tree.Delete(first)
+	return first.(multimapEntry).item, true
+}
+
+// DeleteAll deletes all the items equal to the key and returns their number.
+func (m *Multimap) DeleteAll(key Item) int {
+	lo, hi := m.bounds(key)
+	return m.tree.DeleteRange(lo, hi)
+}
+
+// Ascend calls the fn for every entry in ascending order, and the equal
+// entries in insertion order, until the fn returns false.
+func (m *Multimap) Ascend(fn func(item Item) bool) {
+	m.tree.Ascend(func(item Item) bool {
+		return fn(item.(multimapEntry).item)
+	})
+}
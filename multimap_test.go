@@ -0,0 +1,51 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestMultimap(t *testing.T) {
+	m := NewMultimap(2)
+	for i := 0; i < 30; i++ {
+		m.Insert(&counter{key: i % 3, count: i})
+	}
+	if m.Length() != 30 || m.Count(&counter{key: 1}) != 10 || m.Count(&counter{key: 3}) != 0 {
+		t.Error(m.Length())
+	}
+	items := m.SearchAll(&counter{key: 1})
+	if len(items) != 10 {
+		t.Error(items)
+	}
+	for i, item := range items {
+		if item.(*counter).count != i*3+1 {
+			t.Error(i, item)
+		}
+	}
+	if item, ok := m.Delete(&counter{key: 2}); !ok || item.(*counter).count != 2 {
+		t.Error(item)
+	}
+	if _, ok := m.Delete(&counter{key: 3}); ok {
+		t.Error("")
+	}
+	if m.DeleteAll(&counter{key: 0}) != 10 || m.Length() != 19 {
+		t.Error(m.Length())
+	}
+	var last *counter
+	m.Ascend(func(item Item) bool {
+		c := item.(*counter)
+		if last != nil && (c.key < last.key || c.key == last.key && c.count < last.count) {
+			t.Error(last, c)
+		}
+		last = c
+		return true
+	})
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	m.Insert(nil)
+}
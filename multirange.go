@@ -0,0 +1,35 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Range represents the range [Lo, Hi) of a B-tree. A nil bound leaves the
+// range unbounded on that side.
+type Range struct {
+	Lo Item
+	Hi Item
+}
+
+// MultiRange calls the fn with the index of the range for every item in each
+// of the ranges in ascending order, until the fn returns false.
+//
+// If snapshot is true, the ranges are evaluated against a clone of the B-tree
+// taken before the first range, so the fn may modify the B-tree without
+// affecting the ranges still to be read.
+func (t *Tree) MultiRange(snapshot bool, ranges []Range, fn func(rangeIdx int, item Item) bool) {
+	view := t
+	if snapshot {
+		view = t.Clone()
+		defer view.release()
+	}
+	if view.root == nil {
+		return
+	}
+	stopped := false
+	for i := 0; i < len(ranges) && !stopped; i++ {
+		view.root.ascendRange(ranges[i].Lo, ranges[i].Hi, func(item Item) bool {
+			stopped = !fn(i, item)
+			return !stopped
+		})
+	}
+}
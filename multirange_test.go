@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestMultiRange(t *testing.T) {
+	tree := New(2)
+	tree.MultiRange(false, []Range{{}}, func(rangeIdx int, item Item) bool {
+		t.Error(item)
+		return true
+	})
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	ranges := []Range{{Lo: Int(10), Hi: Int(13)}, {Lo: Int(95)}, {Hi: Int(2)}, {Lo: Int(50), Hi: Int(50)}}
+	var got []int
+	tree.MultiRange(true, ranges, func(rangeIdx int, item Item) bool {
+		got = append(got, rangeIdx, int(item.(Int)))
+		tree.Delete(Int(item.(Int) + 1))
+		tree.Delete(Int(1))
+		return true
+	})
+	want := []int{0, 10, 0, 11, 0, 12, 1, 95, 1, 96, 1, 97, 1, 98, 1, 99, 2, 0, 2, 1}
+	if len(got) != len(want) {
+		t.Error(got)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Error(got)
+				break
+			}
+		}
+	}
+	testShared(tree, t)
+	if tree.Length() != 91 {
+		t.Error(tree.Length())
+	}
+	count := 0
+	tree.MultiRange(false, ranges, func(rangeIdx int, item Item) bool {
+		count++
+		return rangeIdx < 1
+	})
+	if count != 2 {
+		t.Error(count)
+	}
+}
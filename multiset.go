@@ -0,0 +1,105 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Multiset represents a B-tree of items with multiplicities, where inserting
+// an equal item increments its count instead of replacing it.
+type Multiset struct {
+	tree   *Tree
+	length int
+}
+
+type multisetEntry struct {
+	item  Item
+	count int
+}
+
+func (a *multisetEntry) Less(b Item) bool {
+	return a.item.Less(b.(*multisetEntry).item)
+}
+
+// NewMultiset returns a new Multiset with the given degree.
+func NewMultiset(degree int) *Multiset {
+	return &Multiset{tree: New(degree)}
+}
+
+// Length returns the number of items counted with their multiplicities.
+func (m *Multiset) Length() int {
+	return m.length
+}
+
+// Distinct returns the number of distinct items.
+func (m *Multiset) Distinct() int {
+	return m.tree.Length()
+}
+
+// Insert inserts the item and returns its count. The first inserted of the
+// equal items is kept.
+func (m *Multiset) Insert(item Item) int {
+	if item == nil {
+		panic("nil item being inserted to multiset")
+	}
+	actual, _ := m.tree.GetOrInsert(&multisetEntry{item: item})
+	e := actual.(*multisetEntry)
+	e.count++
+	m.length++
+	return e.count
+}
+
+// Delete deletes one of the items equal to the item and returns the remaining count.
+func (m *Multiset) Delete(item Item) int {
+	found := m.tree.Search(&multisetEntry{item: item})
+	if found == nil {
+		return 0
+	}
+	e := found.(*multisetEntry)
+	e.count--
+	m.length--
+	if e.count == 0 {
+		m.tree.Delete(e)
+	}
+	return e.count
+}
+
+// DeleteAll deletes all the items equal to the item and returns their count.
+func (m *Multiset) DeleteAll(item Item) int {
+	removed, ok := m.tree.DeleteItem(&multisetEntry{item: item})
+	if !ok {
+		return 0
+	}
+	count := removed.(*multisetEntry).count
+	m.length -= count
+	return count
+}
+
+// Count returns the count of the items equal to the item.
+func (m *Multiset) Count(item Item) int {
+	if found := m.tree.Search(&multisetEntry{item: item}); found != nil {
+		return found.(*multisetEntry).count
+	}
+	return 0
+}
+
+// Ascend calls the fn for every distinct item with its count in ascending
+// order until the fn returns false.
+func (m *Multiset) Ascend(fn func(item Item, count int) bool) {
+	m.AscendRange(nil, nil, fn)
+}
+
+// AscendRange calls the fn for every distinct item in the range [greaterOrEqual, lessThan)
+// with its count in ascending order until the fn returns false. A nil bound
+// leaves the range unbounded on that side.
+func (m *Multiset) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item, count int) bool) {
+	var lo, hi Item
+	if greaterOrEqual != nil {
+		lo = &multisetEntry{item: greaterOrEqual}
+	}
+	if lessThan != nil {
+		hi = &multisetEntry{item: lessThan}
+	}
+	m.tree.AscendRange(lo, hi, func(item Item) bool {
+		e := item.(*multisetEntry)
+		return fn(e.item, e.count)
+	})
+}
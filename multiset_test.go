@@ -0,0 +1,61 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestMultiset(t *testing.T) {
+	m := NewMultiset(2)
+	for i := 0; i < 10; i++ {
+		for j := 0; j <= i; j++ {
+			if count := m.Insert(Int(i)); count != j+1 {
+				t.Error(i, count)
+			}
+		}
+	}
+	if m.Length() != 55 || m.Distinct() != 10 || m.Count(Int(9)) != 10 || m.Count(Int(10)) != 0 {
+		t.Error(m.Length(), m.Distinct())
+	}
+	if m.Delete(Int(0)) != 0 || m.Delete(Int(0)) != 0 || m.Delete(Int(1)) != 1 {
+		t.Error("")
+	}
+	if m.DeleteAll(Int(9)) != 10 || m.DeleteAll(Int(9)) != 0 {
+		t.Error("")
+	}
+	if m.Length() != 43 || m.Distinct() != 8 {
+		t.Error(m.Length(), m.Distinct())
+	}
+	next, total := 1, 0
+	m.Ascend(func(item Item, count int) bool {
+		want := next + 1
+		if next == 1 {
+			want = 1
+		}
+		if item.(Int) != Int(next) || count != want {
+			t.Error(item, count)
+		}
+		next++
+		total += count
+		return true
+	})
+	if total != m.Length() {
+		t.Error(total)
+	}
+	var got []Item
+	m.AscendRange(Int(3), Int(5), func(item Item, count int) bool {
+		got = append(got, item)
+		return true
+	})
+	if len(got) != 2 || got[0].(Int) != 3 {
+		t.Error(got)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	m.Insert(nil)
+}
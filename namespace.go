@@ -0,0 +1,156 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Namespaces represents the logical trees of several namespaces, such as
+// tenants, kept in one B-tree of items ordered by namespace first.
+type Namespaces struct {
+	tree *Tree
+}
+
+// Namespace represents the logical tree of a namespace.
+type Namespace struct {
+	tree *Tree
+	name string
+}
+
+// nsItem is an item prefixed by its namespace. A nil item orders before all
+// the items of the namespace.
+type nsItem struct {
+	ns   string
+	item Item
+}
+
+func (a nsItem) Less(b Item) bool {
+	x := b.(nsItem)
+	if a.ns != x.ns {
+		return a.ns < x.ns
+	}
+	if a.item == nil || x.item == nil {
+		return a.item == nil && x.item != nil
+	}
+	return a.item.Less(x.item)
+}
+
+// NewNamespaces returns a new Namespaces with the given degree.
+func NewNamespaces(degree int) *Namespaces {
+	return &Namespaces{tree: New(degree)}
+}
+
+// Namespace returns the logical tree of the namespace with the name.
+func (n *Namespaces) Namespace(name string) *Namespace {
+	return &Namespace{tree: n.tree, name: name}
+}
+
+// Length returns the number of items of all namespaces.
+func (n *Namespaces) Length() int {
+	return n.tree.Length()
+}
+
+// Names returns the sorted names of the non-empty namespaces.
+func (n *Namespaces) Names() (names []string) {
+	var lo Item
+	for {
+		it := n.seek(lo)
+		if it == nil {
+			return
+		}
+		name := it.Item().(nsItem).ns
+		names = append(names, name)
+		lo = nsItem{ns: name + "\x00"}
+	}
+}
+
+func (n *Namespaces) seek(lo Item) *Iterator {
+	if n.tree.root == nil {
+		return nil
+	}
+	if lo == nil {
+		return n.tree.root.minIterator()
+	}
+	return n.tree.root.iterator(lo, false)
+}
+
+// Stats returns the number of items of every non-empty namespace.
+func (n *Namespaces) Stats() map[string]int {
+	stats := make(map[string]int)
+	for _, name := range n.Names() {
+		stats[name] = n.Namespace(name).Length()
+	}
+	return stats
+}
+
+// Drop removes all items of the namespace with the name and returns their number.
+func (n *Namespaces) Drop(name string) int {
+	return n.Namespace(name).Clear()
+}
+
+// Name returns the name of the namespace.
+func (ns *Namespace) Name() string {
+	return ns.name
+}
+
+func (ns *Namespace) bounds() (lo, hi Item) {
+	return nsItem{ns: ns.name}, nsItem{ns: ns.name + "\x00"}
+}
+
+func (ns *Namespace) wrap(item Item) Item {
+	if item == nil {
+		panic("nil item being used in namespace")
+	}
+	return nsItem{ns: ns.name, item: item}
+}
+
+// Length returns the number of items of the namespace in O(log n) time.
+func (ns *Namespace) Length() int {
+	lo, hi := ns.bounds()
+	return ns.tree.CountRange(lo, hi)
+}
+
+// Insert inserts the item into the namespace, replacing the equal item.
+func (ns *Namespace) Insert(item Item) {
+	ns.tree.Insert(ns.wrap(item))
+}
+
+// Search searches the item of the namespace.
+func (ns *Namespace) Search(item Item) Item {
+	if found := ns.tree.Search(ns.wrap(item)); found != nil {
+		return found.(nsItem).item
+	}
+	return nil
+}
+
+// Delete deletes the item of the namespace and reports whether it existed.
+func (ns *Namespace) Delete(item Item) bool {
+	_, ok := ns.tree.DeleteItem(ns.wrap(item))
+	return ok
+}
+
+// Clear removes all items of the namespace and returns their number.
+func (ns *Namespace) Clear() int {
+	lo, hi := ns.bounds()
+	return ns.tree.DeleteRange(lo, hi)
+}
+
+// Ascend calls the fn for every item of the namespace in ascending order until
+// the fn returns false.
+func (ns *Namespace) Ascend(fn func(item Item) bool) {
+	ns.AscendRange(nil, nil, fn)
+}
+
+// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan)
+// of the namespace in ascending order until the fn returns false. A nil bound
+// leaves the range unbounded on that side.
+func (ns *Namespace) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool) {
+	lo, hi := ns.bounds()
+	if greaterOrEqual != nil {
+		lo = ns.wrap(greaterOrEqual)
+	}
+	if lessThan != nil {
+		hi = ns.wrap(lessThan)
+	}
+	ns.tree.AscendRange(lo, hi, func(item Item) bool {
+		return fn(item.(nsItem).item)
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	n := NewNamespaces(2)
+	if len(n.Names()) != 0 {
+		t.Error("")
+	}
+	a, b, ab := n.Namespace("a"), n.Namespace("b"), n.Namespace("a\x00b")
+	for i := 0; i < 50; i++ {
+		a.Insert(Int(i))
+		b.Insert(Int(i * 2))
+		if i < 5 {
+			ab.Insert(Int(i))
+		}
+	}
+	if n.Length() != 105 || a.Length() != 50 || b.Length() != 50 || ab.Length() != 5 {
+		t.Error(n.Length(), a.Length(), ab.Length())
+	}
+	names := n.Names()
+	if len(names) != 3 || names[0] != "a" || names[1] != "a\x00b" || names[2] != "b" || a.Name() != "a" {
+		t.Error(names)
+	}
+	if stats := n.Stats(); len(stats) != 3 || stats["b"] != 50 {
+		t.Error(stats)
+	}
+	if a.Search(Int(49)) == nil || a.Search(Int(50)) != nil || b.Search(Int(98)) == nil || b.Search(Int(3)) != nil {
+		t.Error("")
+	}
+	if !a.Delete(Int(0)) || a.Delete(Int(0)) || a.Length() != 49 {
+		t.Error("")
+	}
+	next := 1
+	a.Ascend(func(item Item) bool {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+		return true
+	})
+	if next != 50 {
+		t.Error(next)
+	}
+	count := 0
+	b.AscendRange(Int(10), Int(20), func(item Item) bool {
+		count++
+		return true
+	})
+	if count != 5 {
+		t.Error(count)
+	}
+	if n.Drop("a") != 49 || a.Length() != 0 || ab.Length() != 5 || n.Length() != 55 {
+		t.Error(n.Length())
+	}
+	if names := n.Names(); len(names) != 2 {
+		t.Error(names)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	a.Insert(nil)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package btree
+
+import (
+	"cmp"
+)
+
+// OrderedTree represents a B-tree of values of an ordered type, stored without
+// an Item adapter type.
+type OrderedTree[T cmp.Ordered] struct {
+	tree *Tree
+}
+
+type orderedItem[T cmp.Ordered] struct {
+	v T
+}
+
+func (a orderedItem[T]) Less(b Item) bool {
+	return cmp.Less(a.v, b.(orderedItem[T]).v)
+}
+
+// NewOrdered returns a new OrderedTree with the given degree.
+func NewOrdered[T cmp.Ordered](degree int) *OrderedTree[T] {
+	return &OrderedTree[T]{tree: New(degree)}
+}
+
+// Len returns the number of values in the tree.
+func (t *OrderedTree[T]) Len() int {
+	return t.tree.Length()
+}
+
+// Insert inserts the value and reports whether it was absent.
+func (t *OrderedTree[T]) Insert(v T) bool {
+	_, replaced := t.tree.ReplaceOrInsert(orderedItem[T]{v})
+	return !replaced
+}
+
+// Has reports whether the value is in the tree.
+func (t *OrderedTree[T]) Has(v T) bool {
+	return t.tree.Search(orderedItem[T]{v}) != nil
+}
+
+// Delete deletes the value and reports whether it existed.
+func (t *OrderedTree[T]) Delete(v T) bool {
+	_, ok := t.tree.DeleteItem(orderedItem[T]{v})
+	return ok
+}
+
+// Min returns the min value, and false if the tree is empty.
+func (t *OrderedTree[T]) Min() (v T, ok bool) {
+	if item := t.tree.MinItem(); item != nil {
+		return item.(orderedItem[T]).v, true
+	}
+	return
+}
+
+// Max returns the max value, and false if the tree is empty.
+func (t *OrderedTree[T]) Max() (v T, ok bool) {
+	if item := t.tree.MaxItem(); item != nil {
+		return item.(orderedItem[T]).v, true
+	}
+	return
+}
+
+// Ascend calls the fn for every value in ascending order until the fn returns false.
+func (t *OrderedTree[T]) Ascend(fn func(v T) bool) {
+	t.tree.Ascend(func(item Item) bool {
+		return fn(item.(orderedItem[T]).v)
+	})
+}
+
+// AscendRange calls the fn for every value in the range [greaterOrEqual, lessThan)
+// in ascending order until the fn returns false.
+func (t *OrderedTree[T]) AscendRange(greaterOrEqual, lessThan T, fn func(v T) bool) {
+	t.tree.AscendRange(orderedItem[T]{greaterOrEqual}, orderedItem[T]{lessThan}, func(item Item) bool {
+		return fn(item.(orderedItem[T]).v)
+	})
+}
+
+// Descend calls the fn for every value in descending order until the fn returns false.
+func (t *OrderedTree[T]) Descend(fn func(v T) bool) {
+	t.tree.Descend(func(item Item) bool {
+		return fn(item.(orderedItem[T]).v)
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestOrderedTree(t *testing.T) {
+	tree := NewOrdered[float64](2)
+	if _, ok := tree.Min(); ok {
+		t.Error("")
+	}
+	if _, ok := tree.Max(); ok {
+		t.Error("")
+	}
+	for i := 99; i >= 0; i-- {
+		if !tree.Insert(float64(i) / 2) {
+			t.Error(i)
+		}
+	}
+	if tree.Insert(0.5) || tree.Len() != 100 || !tree.Has(0.5) || tree.Has(0.25) {
+		t.Error(tree.Len())
+	}
+	if !tree.Delete(0.5) || tree.Delete(0.5) || tree.Len() != 99 {
+		t.Error(tree.Len())
+	}
+	if v, ok := tree.Min(); !ok || v != 0 {
+		t.Error(v)
+	}
+	if v, ok := tree.Max(); !ok || v != 49.5 {
+		t.Error(v)
+	}
+	last := -1.0
+	tree.Ascend(func(v float64) bool {
+		if v <= last {
+			t.Error(v)
+		}
+		last = v
+		return true
+	})
+	var got []float64
+	tree.AscendRange(1, 2.5, func(v float64) bool {
+		got = append(got, v)
+		return true
+	})
+	if len(got) != 3 || got[0] != 1 || got[2] != 2 {
+		t.Error(got)
+	}
+	tree.Descend(func(v float64) bool {
+		if v != 49.5 {
+			t.Error(v)
+		}
+		return false
+	})
+	words := NewOrdered[string](3)
+	for _, w := range []string{"b", "c", "a"} {
+		words.Insert(w)
+	}
+	if v, _ := words.Min(); v != "a" {
+		t.Error(v)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry represents a set of named B-trees that can be inspected uniformly.
+//
+// The registry does not lock the trees, so its methods must not be called
+// while the registered trees are being modified.
+type Registry struct {
+	mu    sync.RWMutex
+	trees map[string]*Tree
+}
+
+// RegistryStats represents the statistics of the trees in a registry.
+type RegistryStats struct {
+	// Trees are the statistics of every tree by name.
+	Trees map[string]Stats `json:"trees"`
+	// Length is the total number of items.
+	Length int `json:"length"`
+	// Nodes is the total number of nodes.
+	Nodes int `json:"nodes"`
+}
+
+// NewRegistry returns a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{trees: make(map[string]*Tree)}
+}
+
+// Register registers the tree with the name, replacing any tree with the same name.
+func (r *Registry) Register(name string, t *Tree) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trees[name] = t
+}
+
+// Unregister unregisters the tree with the name and reports whether it was registered.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.trees[name]
+	delete(r.trees, name)
+	return ok
+}
+
+// Tree returns the tree with the name, or nil if there is none.
+func (r *Registry) Tree(name string) *Tree {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.trees[name]
+}
+
+// Names returns the sorted names of the registered trees.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.trees))
+	for name := range r.trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats returns the statistics of every registered tree and their totals.
+func (r *Registry) Stats() RegistryStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s := RegistryStats{Trees: make(map[string]Stats, len(r.trees))}
+	for name, t := range r.trees {
+		stats := t.Stats()
+		s.Trees[name] = stats
+		s.Length += stats.Length
+		s.Nodes += stats.Nodes
+	}
+	return s
+}
+
+// Validate validates the tree with the name.
+func (r *Registry) Validate(name string) error {
+	t := r.Tree(name)
+	if t == nil {
+		return fmt.Errorf("tree %q not registered", name)
+	}
+	return t.Validate()
+}
+
+// Dump writes the items of the tree with the name to the w in ascending order,
+// one per line.
+func (r *Registry) Dump(name string, w io.Writer) (err error) {
+	t := r.Tree(name)
+	if t == nil {
+		return fmt.Errorf("tree %q not registered", name)
+	}
+	t.Ascend(func(item Item) bool {
+		_, err = fmt.Fprintln(w, item)
+		return err == nil
+	})
+	return
+}
+
+// ServeHTTP serves the debug endpoint of the registry. Without the query
+// parameter "tree" it responds with the statistics of all trees in JSON.
+// Otherwise the parameter "op" selects "stats" (default), "validate" or "dump"
+// for the named tree.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("tree")
+	if name == "" {
+		writeJSON(w, r.Stats())
+		return
+	}
+	t := r.Tree(name)
+	if t == nil {
+		http.Error(w, fmt.Sprintf("tree %q not registered", name), http.StatusNotFound)
+		return
+	}
+	switch op := req.URL.Query().Get("op"); op {
+	case "", "stats":
+		writeJSON(w, t.Stats())
+	case "validate":
+		result := struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error,omitempty"`
+		}{OK: true}
+		if err := t.Validate(); err != nil {
+			result.OK, result.Error = false, err.Error()
+		}
+		writeJSON(w, result)
+	case "dump":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		r.Dump(name, w)
+	default:
+		http.Error(w, fmt.Sprintf("unknown op %q", op), http.StatusBadRequest)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	a, b := New(2), New(8)
+	for i := 0; i < 10; i++ {
+		a.Insert(Int(i))
+		b.Insert(Int(i))
+		b.Insert(Int(i + 10))
+	}
+	r.Register("b", b)
+	r.Register("a", a)
+	if names := r.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Error(names)
+	}
+	s := r.Stats()
+	if s.Length != 30 || len(s.Trees) != 2 || s.Trees["a"].Length != 10 || s.Nodes != s.Trees["a"].Nodes+s.Trees["b"].Nodes {
+		t.Error(s)
+	}
+	if r.Validate("a") != nil || r.Validate("c") == nil {
+		t.Error("")
+	}
+	buf := &bytes.Buffer{}
+	if err := r.Dump("a", buf); err != nil || buf.String() != "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n" {
+		t.Error(err, buf.String())
+	}
+	if r.Dump("c", buf) == nil {
+		t.Error("")
+	}
+	if !r.Unregister("b") || r.Unregister("b") || r.Tree("b") != nil || r.Tree("a") != a {
+		t.Error("")
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	tree := New(2)
+	for i := 0; i < 3; i++ {
+		tree.Insert(Int(i))
+	}
+	r.Register("a", tree)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/btree"+query, nil))
+		return w
+	}
+	var s RegistryStats
+	if err := json.Unmarshal(get("").Body.Bytes(), &s); err != nil || s.Length != 3 {
+		t.Error(err, s)
+	}
+	var stats Stats
+	if err := json.Unmarshal(get("?tree=a").Body.Bytes(), &stats); err != nil || stats.Length != 3 {
+		t.Error(err, stats)
+	}
+	if w := get("?tree=a&op=validate"); w.Body.String() != "{\"ok\":true}\n" {
+		t.Error(w.Body.String())
+	}
+	tree.length++
+	if w := get("?tree=a&op=validate"); !bytes.Contains(w.Body.Bytes(), []byte("\"ok\":false")) {
+		t.Error(w.Body.String())
+	}
+	tree.length--
+	if w := get("?tree=a&op=dump"); w.Body.String() != "0\n1\n2\n" {
+		t.Error(w.Body.String())
+	}
+	if w := get("?tree=b"); w.Code != http.StatusNotFound {
+		t.Error(w.Code)
+	}
+	if w := get("?tree=a&op=x"); w.Code != http.StatusBadRequest {
+		t.Error(w.Code)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// ShardedTree represents a B-tree partitioned across several SyncTrees, so
+// writers of different shards do not contend for the same lock.
+//
+// The iterations merge the shards in ascending order. Each shard is cloned
+// when the iteration starts, so an iteration sees a consistent view of every
+// shard but not a single point in time across the shards.
+type ShardedTree struct {
+	shards []*SyncTree
+	shard  func(item Item) int
+}
+
+// NewSharded returns a new ShardedTree with n shards of the given degree.
+// The shard func maps an item to its shard in [0, n), and equal items must
+// map to the same shard.
+func NewSharded(degree, n int, shard func(item Item) int) *ShardedTree {
+	if n < 1 {
+		panic("bad number of shards")
+	}
+	if shard == nil {
+		panic("nil shard func")
+	}
+	t := &ShardedTree{shards: make([]*SyncTree, n), shard: shard}
+	for i := range t.shards {
+		t.shards[i] = NewSync(degree)
+	}
+	return t
+}
+
+// RangeShard returns a shard func that maps the items less than bounds[0] to
+// shard 0, the items in [bounds[i-1], bounds[i]) to shard i, and the items not
+// less than the last bound to shard len(bounds). The bounds must be sorted.
+func RangeShard(bounds []Item) func(item Item) int {
+	return func(item Item) int {
+		return sort.Search(len(bounds), func(i int) bool {
+			return item.Less(bounds[i])
+		})
+	}
+}
+
+// SampleBounds returns n-1 bounds splitting the sorted sample into n ranges of
+// about equal size, for RangeShard.
+func SampleBounds(sample []Item, n int) []Item {
+	if n < 2 || len(sample) == 0 {
+		return nil
+	}
+	bounds := make([]Item, 0, n-1)
+	for i := 1; i < n; i++ {
+		bounds = append(bounds, sample[i*len(sample)/n])
+	}
+	return bounds
+}
+
+// Shards returns the number of shards.
+func (t *ShardedTree) Shards() int {
+	return len(t.shards)
+}
+
+func (t *ShardedTree) shardOf(item Item) *SyncTree {
+	return t.shards[t.shard(item)]
+}
+
+// Length returns the number of items currently in the B-tree.
+func (t *ShardedTree) Length() int {
+	length := 0
+	for _, s := range t.shards {
+		length += s.Length()
+	}
+	return length
+}
+
+// Search searches the Item of the B-tree.
+func (t *ShardedTree) Search(item Item) Item {
+	return t.shardOf(item).Search(item)
+}
+
+// Get returns the item of the B-tree equal to the item and whether it was found.
+func (t *ShardedTree) Get(item Item) (Item, bool) {
+	return t.shardOf(item).Get(item)
+}
+
+// Insert inserts the item into the B-tree.
+func (t *ShardedTree) Insert(item Item) {
+	t.shardOf(item).Insert(item)
+}
+
+// ReplaceOrInsert inserts the item into the B-tree and returns the replaced equal item.
+func (t *ShardedTree) ReplaceOrInsert(item Item) (old Item, replaced bool) {
+	return t.shardOf(item).ReplaceOrInsert(item)
+}
+
+// Delete deletes the node of the B-tree with the item.
+func (t *ShardedTree) Delete(item Item) {
+	t.shardOf(item).Delete(item)
+}
+
+// DeleteItem deletes the item of the B-tree and returns the removed item.
+func (t *ShardedTree) DeleteItem(item Item) (removed Item, ok bool) {
+	return t.shardOf(item).DeleteItem(item)
+}
+
+// Clear removes all items from the B-tree.
+func (t *ShardedTree) Clear() {
+	for _, s := range t.shards {
+		s.Clear()
+	}
+}
+
+// Ascend calls the fn for every item of the B-tree in ascending order until
+// the fn returns false.
+func (t *ShardedTree) Ascend(fn func(item Item) bool) {
+	t.AscendRange(nil, nil, fn)
+}
+
+// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan)
+// of the B-tree in ascending order until the fn returns false. A nil bound
+// leaves the range unbounded on that side.
+func (t *ShardedTree) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool) {
+	h := make(iteratorHeap, 0, len(t.shards))
+	for _, s := range t.shards {
+		clone := s.Clone()
+		defer clone.release()
+		root := clone.root
+		var it *Iterator
+		if greaterOrEqual == nil {
+			it = root.minIterator()
+		} else {
+			it = root.iterator(greaterOrEqual, false)
+		}
+		if it != nil {
+			h = append(h, it)
+		}
+	}
+	heap.Init(&h)
+	for len(h) > 0 {
+		item := h[0].Item()
+		if lessThan != nil && !item.Less(lessThan) {
+			return
+		}
+		if !fn(item) {
+			return
+		}
+		if h[0].Next() == nil {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+}
+
+// iteratorHeap orders the iterators by their items.
+type iteratorHeap []*Iterator
+
+func (h iteratorHeap) Len() int { return len(h) }
+
+func (h iteratorHeap) Less(i, j int) bool { return h[i].Item().Less(h[j].Item()) }
+
+func (h iteratorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *iteratorHeap) Push(x interface{}) { *h = append(*h, x.(*Iterator)) }
+
+func (h *iteratorHeap) Pop() interface{} {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
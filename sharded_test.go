@@ -0,0 +1,150 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedTree(t *testing.T) {
+	tree := NewSharded(3, 4, func(item Item) int {
+		return int(item.(Int)) % 4
+	})
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				tree.Insert(Int(i*8 + w))
+			}
+		}(w)
+	}
+	wg.Wait()
+	if tree.Length() != 800 || tree.Shards() != 4 {
+		t.Error(tree.Length())
+	}
+	next := 0
+	tree.Ascend(func(item Item) bool {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+		return true
+	})
+	if next != 800 {
+		t.Error(next)
+	}
+	for _, s := range tree.shards {
+		if s.tree.root.refs != 1 {
+			t.Error(s.tree.root.refs)
+		}
+	}
+	next = 10
+	tree.AscendRange(Int(10), Int(20), func(item Item) bool {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+		return true
+	})
+	if next != 20 {
+		t.Error(next)
+	}
+	count := 0
+	tree.AscendRange(Int(795), nil, func(item Item) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Error(count)
+	}
+	if tree.Search(Int(5)) == nil || tree.Search(Int(800)) != nil {
+		t.Error("")
+	}
+	if item, ok := tree.Get(Int(5)); !ok || item.(Int) != 5 {
+		t.Error(item)
+	}
+	if _, replaced := tree.ReplaceOrInsert(Int(5)); !replaced {
+		t.Error("")
+	}
+	tree.Delete(Int(5))
+	if _, ok := tree.DeleteItem(Int(6)); !ok || tree.Length() != 798 {
+		t.Error(tree.Length())
+	}
+	for i := 0; i < 800; i += 4 {
+		tree.Delete(Int(i))
+	}
+	count = 0
+	tree.Ascend(func(item Item) bool {
+		if item.(Int)%4 == 0 {
+			t.Error(item)
+		}
+		count++
+		return true
+	})
+	if count != 598 {
+		t.Error(count)
+	}
+	tree.Clear()
+	if tree.Length() != 0 {
+		t.Error(tree.Length())
+	}
+	tree.Ascend(func(item Item) bool {
+		t.Error(item)
+		return true
+	})
+}
+
+func TestRangeShard(t *testing.T) {
+	sample := make([]Item, 100)
+	for i := range sample {
+		sample[i] = Int(i)
+	}
+	bounds := SampleBounds(sample, 4)
+	if len(bounds) != 3 || bounds[0].(Int) != 25 || bounds[2].(Int) != 75 {
+		t.Error(bounds)
+	}
+	if SampleBounds(sample, 1) != nil || SampleBounds(nil, 4) != nil {
+		t.Error("")
+	}
+	shard := RangeShard(bounds)
+	if shard(Int(-1)) != 0 || shard(Int(24)) != 0 || shard(Int(25)) != 1 || shard(Int(99)) != 3 {
+		t.Error("")
+	}
+	tree := NewSharded(2, 4, shard)
+	for i := 99; i >= 0; i-- {
+		tree.Insert(Int(i))
+	}
+	next := 0
+	tree.Ascend(func(item Item) bool {
+		if item.(Int) != Int(next) {
+			t.Error(item, next)
+		}
+		next++
+		return true
+	})
+	for _, s := range tree.shards {
+		if s.Length() != 25 {
+			t.Error(s.Length())
+		}
+	}
+}
+
+func TestNewShardedPanic(t *testing.T) {
+	for _, fn := range []func(){
+		func() { NewSharded(2, 0, func(Item) int { return 0 }) },
+		func() { NewSharded(2, 1, nil) },
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Error("")
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+// Sketch represents a mergeable summary of the ranks of the items,
+// ordered by the items.
+type Sketch struct {
+	Entries []SketchEntry
+}
+
+// SketchEntry represents an item of a sketch and the number of the items it stands for.
+type SketchEntry struct {
+	Item   Item
+	Weight int
+}
+
+// RankSketch returns a sketch with the items at the evenly spaced ranks of the B-tree.
+func (t *Tree) RankSketch(resolution int) Sketch {
+	if resolution <= 0 {
+		panic("bad resolution")
+	}
+	if resolution > t.length {
+		resolution = t.length
+	}
+	s := Sketch{Entries: make([]SketchEntry, 0, resolution)}
+	for k := 0; k < resolution; k++ {
+		start := k * t.length / resolution
+		end := (k + 1) * t.length / resolution
+		s.Entries = append(s.Entries, SketchEntry{Item: t.root.at(start), Weight: end - start})
+	}
+	return s
+}
+
+// Total returns the number of the items the sketch stands for.
+func (s Sketch) Total() (total int) {
+	for _, e := range s.Entries {
+		total += e.Weight
+	}
+	return
+}
+
+// Merge returns the sketch merged from this sketch and the other sketch.
+func (s Sketch) Merge(other Sketch) Sketch {
+	merged := Sketch{Entries: make([]SketchEntry, 0, len(s.Entries)+len(other.Entries))}
+	i, j := 0, 0
+	for i < len(s.Entries) && j < len(other.Entries) {
+		if other.Entries[j].Item.Less(s.Entries[i].Item) {
+			merged.Entries = append(merged.Entries, other.Entries[j])
+			j++
+		} else {
+			merged.Entries = append(merged.Entries, s.Entries[i])
+			i++
+		}
+	}
+	merged.Entries = append(merged.Entries, s.Entries[i:]...)
+	merged.Entries = append(merged.Entries, other.Entries[j:]...)
+	return merged
+}
+
+// Quantile returns the approximate item at the quantile q in [0, 1].
+func (s Sketch) Quantile(q float64) Item {
+	if len(s.Entries) == 0 {
+		return nil
+	}
+	target := int(q * float64(s.Total()))
+	cum := 0
+	for _, e := range s.Entries {
+		cum += e.Weight
+		if cum > target {
+			return e.Item
+		}
+	}
+	return s.Entries[len(s.Entries)-1].Item
+}
+
+// Rank returns the approximate number of the items less than the item.
+func (s Sketch) Rank(item Item) (rank int) {
+	for _, e := range s.Entries {
+		if !e.Item.Less(item) {
+			break
+		}
+		rank += e.Weight
+	}
+	return
+}
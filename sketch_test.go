@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+	for d := 2; d < 5; d++ {
+		tree := New(d)
+		for i := 0; i < 300; i++ {
+			tree.Insert(Int(i))
+		}
+		for i := 0; i < 300; i++ {
+			if item := tree.root.at(i); item.(Int) != Int(i) {
+				t.Error(item, i)
+			}
+		}
+	}
+}
+
+func TestRankSketch(t *testing.T) {
+	a := New(3)
+	b := New(3)
+	for i := 0; i < 1000; i++ {
+		if i%2 == 0 {
+			a.Insert(Int(i))
+		} else {
+			b.Insert(Int(i))
+		}
+	}
+	sa := a.RankSketch(50)
+	sb := b.RankSketch(50)
+	if len(sa.Entries) != 50 || sa.Total() != 500 {
+		t.Error(len(sa.Entries), sa.Total())
+	}
+	merged := sa.Merge(sb)
+	if merged.Total() != 1000 || len(merged.Entries) != 100 {
+		t.Error(merged.Total(), len(merged.Entries))
+	}
+	for i := 1; i < len(merged.Entries); i++ {
+		if merged.Entries[i].Item.Less(merged.Entries[i-1].Item) {
+			t.Error(i)
+		}
+	}
+	for _, q := range []float64{0, 0.1, 0.5, 0.9, 1} {
+		item := merged.Quantile(q)
+		if diff := int(item.(Int)) - int(q*1000); diff > 20 || diff < -20 {
+			t.Error(q, item)
+		}
+	}
+	if rank := merged.Rank(Int(500)); rank < 480 || rank > 520 {
+		t.Error(rank)
+	}
+	small := New(2)
+	small.Insert(Int(1))
+	s := small.RankSketch(10)
+	if len(s.Entries) != 1 || s.Entries[0].Weight != 1 || s.Quantile(0.5).(Int) != 1 {
+		t.Error(s)
+	}
+	if (Sketch{}).Quantile(0.5) != nil {
+		t.Error("")
+	}
+	if len(New(2).RankSketch(10).Entries) != 0 {
+		t.Error("")
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	small.RankSketch(0)
+}
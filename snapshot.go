@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sort"
+	"time"
+)
+
+// SnapshotInfo represents the metadata of a snapshot.
+type SnapshotInfo struct {
+	Label  string
+	Time   time.Time
+	Length int
+}
+
+type snapshot struct {
+	info SnapshotInfo
+	tree *Tree
+}
+
+// CommitSnapshot commits a copy-on-write snapshot of the B-tree with the label.
+// A snapshot with the same label is replaced, and must not be used afterwards.
+func (t *Tree) CommitSnapshot(label string) {
+	if t.snapshots == nil {
+		t.snapshots = make(map[string]*snapshot)
+	}
+	if s, ok := t.snapshots[label]; ok {
+		s.tree.release()
+	}
+	t.snapshots[label] = &snapshot{
+		info: SnapshotInfo{Label: label, Time: t.now(), Length: t.length},
+		tree: t.Clone(),
+	}
+}
+
+// Snapshot returns the snapshot of the B-tree with the label. The snapshot is a
+// clone sharing the nodes with the B-tree, so the iterators of its min and max
+// nodes cover the whole snapshot, while the iterators of its other nodes stop
+// at the nodes still shared with the B-tree, as described by Node.Iterator.
+func (t *Tree) Snapshot(label string) *Tree {
+	if s, ok := t.snapshots[label]; ok {
+		return s.tree
+	}
+	return nil
+}
+
+// ListSnapshots returns the metadata of the snapshots ordered by time.
+func (t *Tree) ListSnapshots() []SnapshotInfo {
+	infos := make([]SnapshotInfo, 0, len(t.snapshots))
+	for _, s := range t.snapshots {
+		infos = append(infos, s.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Time.Equal(infos[j].Time) {
+			return infos[i].Label < infos[j].Label
+		}
+		return infos[i].Time.Before(infos[j].Time)
+	})
+	return infos
+}
+
+// DropSnapshot drops the snapshot of the B-tree with the label, which must not
+// be used afterwards.
+func (t *Tree) DropSnapshot(label string) bool {
+	s, ok := t.snapshots[label]
+	if !ok {
+		return false
+	}
+	s.tree.release()
+	delete(t.snapshots, label)
+	return true
+}
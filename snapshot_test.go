@@ -0,0 +1,269 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	tree := New(2)
+	if tree.Snapshot("none") != nil || len(tree.ListSnapshots()) != 0 || tree.DropSnapshot("none") {
+		t.Error("")
+	}
+	for i := 0; i < 64; i++ {
+		tree.Insert(Int(i))
+	}
+	tree.CommitSnapshot("a")
+	for i := 64; i < 128; i++ {
+		tree.Insert(Int(i))
+	}
+	tree.CommitSnapshot("b")
+	for i := 0; i < 32; i++ {
+		tree.Delete(Int(i))
+	}
+	testShared(tree, t)
+	a := tree.Snapshot("a")
+	b := tree.Snapshot("b")
+	testShared(a, t)
+	testShared(b, t)
+	if a.Length() != 64 || b.Length() != 128 || tree.Length() != 96 {
+		t.Error(a.Length(), b.Length(), tree.Length())
+	}
+	if a.Search(Int(0)) == nil || a.Search(Int(64)) != nil || b.Search(Int(0)) == nil || tree.Search(Int(0)) != nil {
+		t.Error("")
+	}
+	infos := tree.ListSnapshots()
+	if len(infos) != 2 || infos[0].Label != "a" || infos[0].Length != 64 || infos[1].Label != "b" || infos[1].Length != 128 {
+		t.Error(infos)
+	}
+	b.Insert(Int(-1))
+	testShared(b, t)
+	if b.Length() != 129 || tree.Search(Int(-1)) != nil || a.Search(Int(-1)) != nil {
+		t.Error("")
+	}
+	if !tree.DropSnapshot("a") || tree.Snapshot("a") != nil || len(tree.ListSnapshots()) != 1 {
+		t.Error("")
+	}
+	tree.CommitSnapshot("c")
+	tree.Clear()
+	if tree.Snapshot("c").Length() != 96 || tree.Length() != 0 {
+		t.Error("")
+	}
+}
+
+func TestSnapshotRelease(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 64; i++ {
+		tree.Insert(Int(i))
+	}
+	root := tree.Root()
+	tree.CommitSnapshot("a")
+	tree.CommitSnapshot("a")
+	tree.ReplaceOrInsert(Int(10))
+	if tree.Root() == root {
+		t.Error("shared nodes mutated")
+	}
+	root = tree.Root()
+	tree.CommitSnapshot("b")
+	tree.CommitSnapshot("b")
+	tree.DropSnapshot("a")
+	tree.DropSnapshot("b")
+	tree.ReplaceOrInsert(Int(20))
+	if tree.Root() != root {
+		t.Error("nodes copied after the snapshots were dropped")
+	}
+	testShared(tree, t)
+}
+
+func TestClone(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 256; i++ {
+		tree.Insert(Int(i))
+	}
+	clone := tree.Clone()
+	iter, _ := clone.DeleteAndNext(Int(10))
+	if iter.Item().(Int) != 11 || tree.Search(Int(10)) == nil {
+		t.Error("")
+	}
+	tree.Delete(Int(20))
+	if clone.Search(Int(20)) == nil || tree.Search(Int(20)) != nil {
+		t.Error("")
+	}
+	testShared(tree, t)
+	testShared(clone, t)
+}
+
+func TestClonePathCopy(t *testing.T) {
+	tree := New(3)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(Int(i))
+	}
+	clone := tree.Clone()
+	tree.Insert(Int(1000))
+	shared := make(map[*Node]bool)
+	var walk func(n *Node, fn func(n *Node))
+	walk = func(n *Node, fn func(n *Node)) {
+		fn(n)
+		for _, child := range n.children {
+			walk(child, fn)
+		}
+	}
+	walk(clone.Root(), func(n *Node) { shared[n] = true })
+	copied := 0
+	walk(tree.Root(), func(n *Node) {
+		if !shared[n] {
+			copied++
+		}
+	})
+	if copied > tree.Root().Depth()*3 {
+		t.Error(copied)
+	}
+	if clone.Length() != 1000 || clone.Search(Int(1000)) != nil {
+		t.Error(clone.Length())
+	}
+	clone.release()
+	root := tree.Root()
+	tree.Insert(Int(1001))
+	if tree.Root() != root {
+		t.Error("root copied after the clone was released")
+	}
+	testShared(tree, t)
+}
+
+func TestCloneDiverge(t *testing.T) {
+	trees := []*Tree{New(2)}
+	models := []map[int]bool{{}}
+	seed := uint32(1)
+	next := func(n int) int {
+		seed = seed*1664525 + 1013904223
+		return int(seed>>8) % n
+	}
+	for i := 0; i < 20000; i++ {
+		j := next(len(trees))
+		tree, model := trees[j], models[j]
+		switch key := next(300); next(11) {
+		case 0:
+			if len(trees) < 8 {
+				clone := make(map[int]bool, len(model))
+				for k := range model {
+					clone[k] = true
+				}
+				trees, models = append(trees, tree.Clone()), append(models, clone)
+			}
+		case 1:
+			if len(trees) > 1 {
+				tree.release()
+				trees, models = append(trees[:j], trees[j+1:]...), append(models[:j], models[j+1:]...)
+			}
+		case 2, 3, 4, 5:
+			tree.Insert(Int(key))
+			model[key] = true
+		case 6:
+			tree.Update(Int(key), func(old Item, found bool) (Item, bool) {
+				return Int(key), true
+			})
+			model[key] = true
+		case 7:
+			tree.DeleteAndNext(Int(key))
+			delete(model, key)
+		case 8:
+			tree.InsertBatch([]Item{Int(key), Int(key + 1), Int(key + 5)})
+			model[key], model[key+1], model[key+5] = true, true, true
+		default:
+			tree.Delete(Int(key))
+			delete(model, key)
+		}
+	}
+	for j, tree := range trees {
+		if tree.Length() != len(models[j]) {
+			t.Error(j, tree.Length(), len(models[j]))
+		}
+		for key := range models[j] {
+			if tree.Search(Int(key)) == nil {
+				t.Error(j, key)
+			}
+		}
+		testShared(tree, t)
+	}
+}
+
+func TestCloneIterator(t *testing.T) {
+	a := New(2)
+	for i := 0; i < 100; i++ {
+		a.Insert(Int(i * 2))
+	}
+	b := a.Clone()
+	b.Insert(Int(1))
+	for i := 0; i < 100; i += 3 {
+		a.Insert(Int(i*2 + 1))
+		a.Delete(Int(i * 2))
+	}
+	for _, tree := range []*Tree{a, b} {
+		count := 0
+		for iter := tree.Min().MinIterator(); iter != nil; iter = iter.Next() {
+			count++
+		}
+		if count != tree.Length() {
+			t.Error(count, tree.Length())
+		}
+		count = 0
+		for iter := tree.Max().MaxIterator(); iter != nil; iter = iter.Last() {
+			count++
+		}
+		if count != tree.Length() {
+			t.Error(count, tree.Length())
+		}
+		for i := 0; i < 200; i++ {
+			n := tree.SearchNode(Int(i))
+			if n == nil {
+				continue
+			}
+			for p := n; p != nil; p = p.Parent() {
+				if p.Parent() == nil && p != tree.Root() && p.linked() {
+					t.Error(i)
+				}
+			}
+			j, _ := n.items.search(Int(i))
+			iter := n.Iterator(j)
+			for ; iter != nil; iter = iter.Next() {
+				if tree.Search(iter.Item()) == nil {
+					t.Error(i, iter.Item())
+				}
+			}
+		}
+		testShared(tree, t)
+	}
+}
+
+func TestCloneRefs(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(Int(i))
+	}
+	clone := tree.Clone()
+	tree.Insert(Int(500))
+	clone.Insert(Int(500))
+	clone.release()
+	nodes := make(map[*Node]bool)
+	testWalk(tree.Root(), func(n *Node) {
+		nodes[n] = true
+		if n.shared() {
+			t.Error("shared node after the clone was released")
+		}
+	})
+	for i := 0; i < 1000; i += 7 {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	copied := 0
+	testWalk(tree.Root(), func(n *Node) {
+		if !nodes[n] {
+			copied++
+		}
+	})
+	if copied != 0 {
+		t.Error(copied)
+	}
+	testShared(tree, t)
+}
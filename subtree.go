@@ -0,0 +1,135 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"errors"
+)
+
+// ErrRange is returned when an item out of the range of a SubTree is written.
+var ErrRange = errors.New("item out of range")
+
+// SubTree represents a live view of the items of a B-tree in a key range, so a
+// component can be handed a narrowed window of a shared B-tree. The reads see
+// the later mutations of the B-tree, and the writes out of the range are rejected.
+type SubTree struct {
+	tree   *Tree
+	lo, hi Item
+}
+
+// SubTree returns a live view of the items in the range [lo, hi) of the B-tree.
+// A nil bound leaves the range unbounded on that side.
+func (t *Tree) SubTree(lo, hi Item) *SubTree {
+	return &SubTree{tree: t, lo: lo, hi: hi}
+}
+
+// Contains reports whether the item is within the range of the view.
+func (s *SubTree) Contains(item Item) bool {
+	return (s.lo == nil || !item.Less(s.lo)) && (s.hi == nil || item.Less(s.hi))
+}
+
+// Length returns the number of items in the range in O(log n) time.
+func (s *SubTree) Length() int {
+	return s.tree.CountRange(s.lo, s.hi)
+}
+
+// Search searches the item in the range.
+func (s *SubTree) Search(item Item) Item {
+	if !s.Contains(item) {
+		return nil
+	}
+	return s.tree.Search(item)
+}
+
+// Get returns the item in the range equal to the item and whether it was found.
+func (s *SubTree) Get(item Item) (Item, bool) {
+	found := s.Search(item)
+	return found, found != nil
+}
+
+// MinItem returns the min item in the range.
+func (s *SubTree) MinItem() (min Item) {
+	s.Ascend(func(item Item) bool {
+		min = item
+		return false
+	})
+	return
+}
+
+// MaxItem returns the max item in the range.
+func (s *SubTree) MaxItem() (max Item) {
+	s.Descend(func(item Item) bool {
+		max = item
+		return false
+	})
+	return
+}
+
+// Insert inserts the item into the range, or returns ErrRange if it is out of the range.
+func (s *SubTree) Insert(item Item) error {
+	_, err := s.ReplaceOrInsert(item)
+	return err
+}
+
+// ReplaceOrInsert inserts the item into the range and returns the replaced
+// item, or returns ErrRange if it is out of the range.
+func (s *SubTree) ReplaceOrInsert(item Item) (Item, error) {
+	if item == nil {
+		panic("nil item being inserted to tree")
+	}
+	if !s.Contains(item) {
+		return nil, ErrRange
+	}
+	old, _ := s.tree.ReplaceOrInsert(item)
+	return old, nil
+}
+
+// Delete deletes the item in the range and returns the removed item.
+func (s *SubTree) Delete(item Item) (removed Item, ok bool) {
+	if !s.Contains(item) {
+		return nil, false
+	}
+	return s.tree.DeleteItem(item)
+}
+
+// Clear removes all items in the range and returns the number of the removed items.
+func (s *SubTree) Clear() int {
+	return s.tree.DeleteRange(s.lo, s.hi)
+}
+
+// Ascend calls the fn for every item in the range in ascending order until
+// the fn returns false.
+func (s *SubTree) Ascend(fn func(item Item) bool) {
+	s.tree.root.ascendRange(s.lo, s.hi, fn)
+}
+
+// AscendRange calls the fn for every item in both the range [greaterOrEqual,
+// lessThan) and the range of the view in ascending order until the fn returns false.
+func (s *SubTree) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool) {
+	lo, hi := s.lo, s.hi
+	if lo == nil || greaterOrEqual != nil && lo.Less(greaterOrEqual) {
+		lo = greaterOrEqual
+	}
+	if hi == nil || lessThan != nil && lessThan.Less(hi) {
+		hi = lessThan
+	}
+	if lo != nil && hi != nil && !lo.Less(hi) {
+		return
+	}
+	s.tree.root.ascendRange(lo, hi, fn)
+}
+
+// Descend calls the fn for every item in the range in descending order until
+// the fn returns false.
+func (s *SubTree) Descend(fn func(item Item) bool) {
+	s.tree.root.descendRange(s.hi, nil, func(item Item) bool {
+		if s.hi != nil && !item.Less(s.hi) {
+			return true
+		}
+		if s.lo != nil && item.Less(s.lo) {
+			return false
+		}
+		return fn(item)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"testing"
+)
+
+func TestSubTree(t *testing.T) {
+	tree := New(2)
+	for i := 0; i < 100; i++ {
+		tree.Insert(Int(i))
+	}
+	s := tree.SubTree(Int(10), Int(20))
+	if s.Length() != 10 || s.MinItem().(Int) != 10 || s.MaxItem().(Int) != 19 {
+		t.Error(s.Length())
+	}
+	if s.Search(Int(20)) != nil || s.Search(Int(9)) != nil || s.Search(Int(15)).(Int) != 15 {
+		t.Error("")
+	}
+	if item, ok := s.Get(Int(10)); !ok || item.(Int) != 10 {
+		t.Error(item)
+	}
+	if s.Insert(Int(20)) != ErrRange || s.Insert(Int(-1)) != ErrRange || tree.Length() != 100 {
+		t.Error("")
+	}
+	if _, ok := s.Delete(Int(30)); ok || tree.Length() != 100 {
+		t.Error("")
+	}
+	if removed, ok := s.Delete(Int(15)); !ok || removed.(Int) != 15 || s.Length() != 9 {
+		t.Error(removed)
+	}
+	if old, err := s.ReplaceOrInsert(Int(15)); err != nil || old != nil || tree.Length() != 100 {
+		t.Error(old, err)
+	}
+	tree.Delete(Int(10))
+	if s.Length() != 9 || s.MinItem().(Int) != 11 {
+		t.Error(s.Length())
+	}
+	var items []Item
+	s.Ascend(func(item Item) bool {
+		items = append(items, item)
+		return true
+	})
+	s.AscendRange(Int(0), Int(13), func(item Item) bool {
+		items = append(items, item)
+		return true
+	})
+	s.AscendRange(Int(18), nil, func(item Item) bool {
+		items = append(items, item)
+		return true
+	})
+	s.AscendRange(Int(30), Int(40), func(item Item) bool {
+		items = append(items, item)
+		return true
+	})
+	s.Descend(func(item Item) bool {
+		items = append(items, item)
+		return item.(Int) > 18
+	})
+	if len(items) != 9+2+2+2 || items[9].(Int) != 11 || items[11].(Int) != 18 || items[13].(Int) != 19 || items[14].(Int) != 18 {
+		t.Error(items)
+	}
+	if s.Clear() != 9 || s.Length() != 0 || s.MinItem() != nil || s.MaxItem() != nil || tree.Length() != 90 {
+		t.Error(tree.Length())
+	}
+	all := tree.SubTree(nil, nil)
+	if all.Length() != 90 || all.MaxItem().(Int) != 99 || !all.Contains(Int(1000)) {
+		t.Error(all.Length())
+	}
+}
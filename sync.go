@@ -0,0 +1,181 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+)
+
+// SyncTree represents a B-tree safe for concurrent use. Readers share a read
+// lock and writers are serialized by the write lock.
+//
+// The scans hold the read lock while calling the fn, so the fn must not call
+// the writing methods of the same SyncTree. Iterators are not exposed since
+// they cannot hold the lock; iterate a Clone instead.
+type SyncTree struct {
+	mu   sync.RWMutex
+	tree *Tree
+}
+
+// NewSync returns a new SyncTree with the given degree.
+func NewSync(degree int) *SyncTree {
+	return &SyncTree{tree: New(degree)}
+}
+
+// Length returns the number of items currently in the B-tree.
+func (t *SyncTree) Length() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Length()
+}
+
+// Search searches the Item of the B-tree.
+func (t *SyncTree) Search(item Item) Item {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Search(item)
+}
+
+// Get returns the item of the B-tree equal to the item and whether it was found.
+func (t *SyncTree) Get(item Item) (Item, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Get(item)
+}
+
+// MaxItem returns the max item of the B-tree.
+func (t *SyncTree) MaxItem() Item {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.MaxItem()
+}
+
+// MinItem returns the min item of the B-tree.
+func (t *SyncTree) MinItem() Item {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.MinItem()
+}
+
+// Insert inserts the item into the B-tree.
+func (t *SyncTree) Insert(item Item) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.Insert(item)
+}
+
+// ReplaceOrInsert inserts the item into the B-tree and returns the replaced equal item.
+func (t *SyncTree) ReplaceOrInsert(item Item) (old Item, replaced bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.ReplaceOrInsert(item)
+}
+
+// GetOrInsert returns the existing item equal to the item if present,
+// otherwise it inserts the item into the B-tree and returns it.
+func (t *SyncTree) GetOrInsert(item Item) (actual Item, loaded bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.GetOrInsert(item)
+}
+
+// Update calls the fn with the item of the B-tree equal to the item and whether
+// it exists, and stores the item returned by the fn if the fn returns true.
+// The fn is called with the write lock held.
+func (t *SyncTree) Update(item Item, fn func(old Item, exists bool) (Item, bool)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.Update(item, fn)
+}
+
+// Delete deletes the node of the B-tree with the item.
+func (t *SyncTree) Delete(item Item) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.Delete(item)
+}
+
+// DeleteItem deletes the item of the B-tree and returns the removed item.
+func (t *SyncTree) DeleteItem(item Item) (removed Item, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.DeleteItem(item)
+}
+
+// DeleteMin deletes the min item of the B-tree and returns it.
+func (t *SyncTree) DeleteMin() (Item, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.DeleteMin()
+}
+
+// DeleteMax deletes the max item of the B-tree and returns it.
+func (t *SyncTree) DeleteMax() (Item, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.DeleteMax()
+}
+
+// Clear removes all items from the B-tree.
+func (t *SyncTree) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tree.Clear()
+}
+
+// Clone returns a lazy copy of the B-tree, which can be read and iterated
+// without locking.
+func (t *SyncTree) Clone() *Tree {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tree.Clone()
+}
+
+// Ascend calls the fn for every item of the B-tree in ascending order until
+// the fn returns false.
+func (t *SyncTree) Ascend(fn func(item Item) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.Ascend(fn)
+}
+
+// AscendRange calls the fn for every item in the range [greaterOrEqual, lessThan)
+// of the B-tree in ascending order until the fn returns false.
+func (t *SyncTree) AscendRange(greaterOrEqual, lessThan Item, fn func(item Item) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.AscendRange(greaterOrEqual, lessThan, fn)
+}
+
+// AscendGreaterOrEqual calls the fn for every item greater than or equal to the pivot
+// of the B-tree in ascending order until the fn returns false.
+func (t *SyncTree) AscendGreaterOrEqual(pivot Item, fn func(item Item) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.AscendGreaterOrEqual(pivot, fn)
+}
+
+// Descend calls the fn for every item of the B-tree in descending order until
+// the fn returns false.
+func (t *SyncTree) Descend(fn func(item Item) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.Descend(fn)
+}
+
+// DescendRange calls the fn for every item in the range (greaterThan, lessOrEqual]
+// of the B-tree in descending order until the fn returns false.
+func (t *SyncTree) DescendRange(lessOrEqual, greaterThan Item, fn func(item Item) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.DescendRange(lessOrEqual, greaterThan, fn)
+}
+
+// DescendLessOrEqual calls the fn for every item less than or equal to the pivot
+// of the B-tree in descending order until the fn returns false.
+func (t *SyncTree) DescendLessOrEqual(pivot Item, fn func(item Item) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.tree.DescendLessOrEqual(pivot, fn)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncTree(t *testing.T) {
+	tree := NewSync(3)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				tree.Insert(Int(w*250 + i))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				tree.Search(Int(i))
+				tree.Ascend(func(item Item) bool {
+					return item.(Int) < 10
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if tree.Length() != 1000 {
+		t.Error(tree.Length())
+	}
+	clone := tree.Clone()
+	testTraversal(clone, t)
+	if tree.MinItem().(Int) != 0 || tree.MaxItem().(Int) != 999 {
+		t.Error("")
+	}
+	if item, ok := tree.Get(Int(1)); !ok || item.(Int) != 1 || tree.Search(Int(1)) == nil {
+		t.Error("")
+	}
+	if _, replaced := tree.ReplaceOrInsert(Int(1)); !replaced {
+		t.Error("")
+	}
+	if _, loaded := tree.GetOrInsert(Int(1000)); loaded {
+		t.Error("")
+	}
+	tree.Update(Int(1001), func(old Item, exists bool) (Item, bool) {
+		return Int(1001), true
+	})
+	tree.Delete(Int(1001))
+	if _, ok := tree.DeleteItem(Int(1000)); !ok {
+		t.Error("")
+	}
+	if item, ok := tree.DeleteMin(); !ok || item.(Int) != 0 {
+		t.Error(item)
+	}
+	if item, ok := tree.DeleteMax(); !ok || item.(Int) != 999 {
+		t.Error(item)
+	}
+	count := 0
+	fn := func(item Item) bool {
+		count++
+		return true
+	}
+	tree.AscendRange(Int(10), Int(20), fn)
+	tree.AscendGreaterOrEqual(Int(990), fn)
+	tree.Descend(fn)
+	tree.DescendRange(Int(20), Int(10), fn)
+	tree.DescendLessOrEqual(Int(9), fn)
+	if count != 10+9+998+10+9 {
+		t.Error(count)
+	}
+	tree.Clear()
+	if tree.Length() != 0 || clone.Length() != 1000 {
+		t.Error("")
+	}
+}
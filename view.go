@@ -0,0 +1,104 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// View represents a read view pinning a copy-on-write snapshot of a B-tree
+// until it is closed. While any view is open, the mutations of the B-tree copy
+// the nodes on their paths still shared with the view. Closing a view releases
+// its references to the nodes, so the nodes shared with no other view or clone
+// are mutated in place again; closing the views early avoids the copies.
+//
+// A view can be read and closed concurrently with the mutations of its B-tree,
+// but must not be used after it is closed.
+type View struct {
+	ReadOnlyTree
+	tree     *Tree
+	views    *views
+	acquired time.Time
+	caller   string
+	closed   int32
+}
+
+// ViewLeak describes a view held for too long.
+type ViewLeak struct {
+	// Acquired is the time the view was acquired.
+	Acquired time.Time
+	// Held is the duration the view has been held for.
+	Held time.Duration
+	// Caller is the file and line of the call to AcquireView.
+	Caller string
+}
+
+// views tracks the open views of a tree.
+type views struct {
+	mu   sync.Mutex
+	open map[*View]struct{}
+}
+
+// AcquireView returns a new view of the B-tree, which must be closed by Close.
+func (t *Tree) AcquireView() *View {
+	if t.views == nil {
+		t.views = &views{open: make(map[*View]struct{})}
+	}
+	clone := t.Clone()
+	v := &View{ReadOnlyTree: frozen{t: clone}, tree: clone, views: t.views, acquired: t.now()}
+	if _, file, line, ok := runtime.Caller(1); ok {
+		v.caller = fmt.Sprintf("%s:%d", file, line)
+	}
+	t.views.mu.Lock()
+	t.views.open[v] = struct{}{}
+	t.views.mu.Unlock()
+	return v
+}
+
+// Close releases the snapshot pinned by the view. Closing a closed view has no effect.
+func (v *View) Close() error {
+	if !atomic.CompareAndSwapInt32(&v.closed, 0, 1) {
+		return nil
+	}
+	v.views.mu.Lock()
+	delete(v.views.open, v)
+	v.views.mu.Unlock()
+	v.tree.release()
+	return nil
+}
+
+// OpenViews returns the number of the open views of the B-tree.
+func (t *Tree) OpenViews() int {
+	if t.views == nil {
+		return 0
+	}
+	t.views.mu.Lock()
+	defer t.views.mu.Unlock()
+	return len(t.views.open)
+}
+
+// LeakedViews returns the open views of the B-tree held for longer than the
+// given duration, the longest held first.
+func (t *Tree) LeakedViews(d time.Duration) (leaks []ViewLeak) {
+	if t.views == nil {
+		return
+	}
+	now := t.now()
+	t.views.mu.Lock()
+	for v := range t.views.open {
+		if held := now.Sub(v.acquired); held > d {
+			leaks = append(leaks, ViewLeak{Acquired: v.acquired, Held: held, Caller: v.caller})
+		}
+	}
+	t.views.mu.Unlock()
+	sort.Slice(leaks, func(i, j int) bool {
+		return leaks[i].Held > leaks[j].Held
+	})
+	return
+}
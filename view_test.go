@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestView(t *testing.T) {
+	tree := New(2)
+	if tree.OpenViews() != 0 || len(tree.LeakedViews(0)) != 0 {
+		t.Error("")
+	}
+	for i := 0; i < 10; i++ {
+		tree.Insert(Int(i))
+	}
+	v := tree.AcquireView()
+	tree.Insert(Int(10))
+	if v.Length() != 10 || tree.Length() != 11 || v.Search(Int(10)) != nil {
+		t.Error(v.Length())
+	}
+	if tree.OpenViews() != 1 {
+		t.Error(tree.OpenViews())
+	}
+	time.Sleep(time.Millisecond * 2)
+	w := tree.AcquireView()
+	leaks := tree.LeakedViews(time.Millisecond)
+	if len(leaks) != 1 || !strings.Contains(leaks[0].Caller, "view_test.go") || leaks[0].Held < time.Millisecond {
+		t.Error(leaks)
+	}
+	if len(tree.LeakedViews(0)) != 2 {
+		t.Error("")
+	}
+	if v.Close() != nil || v.Close() != nil || tree.OpenViews() != 1 {
+		t.Error(tree.OpenViews())
+	}
+	w.Close()
+	if tree.OpenViews() != 0 || len(tree.LeakedViews(0)) != 0 {
+		t.Error("")
+	}
+	min := tree.Min()
+	tree.Insert(Int(11))
+	if tree.Min() != min {
+		t.Error("copied after the views were closed")
+	}
+	testShared(tree, t)
+}
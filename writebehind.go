@@ -0,0 +1,165 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ErrClosed is returned when a mutation is enqueued onto a closed WriteBehind.
+var ErrClosed = errors.New("write-behind closed")
+
+// WriteBehind represents a write-behind queue of a SyncTree. Insert and Delete
+// enqueue the mutations onto a lock-free queue without waiting, and a single
+// applier goroutine applies them to the tree in batches, each under one write
+// lock. The mutations are applied in the order they were enqueued.
+type WriteBehind struct {
+	tree    *SyncTree
+	head    unsafe.Pointer
+	tail    *writeOp
+	wake    chan struct{}
+	done    chan struct{}
+	closed  int32
+	pushing int32
+}
+
+// writeOp is a queued mutation, or a flush marker if flushed is not nil.
+type writeOp struct {
+	next    unsafe.Pointer
+	item    Item
+	delete  bool
+	flushed chan struct{}
+}
+
+// NewWriteBehind returns a new WriteBehind of the tree and starts its applier.
+func NewWriteBehind(tree *SyncTree) *WriteBehind {
+	stub := &writeOp{}
+	w := &WriteBehind{
+		tree: tree,
+		head: unsafe.Pointer(stub),
+		tail: stub,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Tree returns the tree the mutations are applied to.
+func (w *WriteBehind) Tree() *SyncTree {
+	return w.tree
+}
+
+// Insert enqueues the insertion of the item. It returns ErrClosed if the
+// WriteBehind is closed, and otherwise the insertion is applied before Close
+// returns.
+func (w *WriteBehind) Insert(item Item) error {
+	if item == nil {
+		panic("nil item being inserted to tree")
+	}
+	return w.push(&writeOp{item: item})
+}
+
+// Delete enqueues the deletion of the item. It returns ErrClosed if the
+// WriteBehind is closed, and otherwise the deletion is applied before Close
+// returns.
+func (w *WriteBehind) Delete(item Item) error {
+	return w.push(&writeOp{item: item, delete: true})
+}
+
+// Flush waits until the mutations enqueued before are applied.
+func (w *WriteBehind) Flush() {
+	op := &writeOp{flushed: make(chan struct{})}
+	w.push(op)
+	<-op.flushed
+}
+
+// Close applies the enqueued mutations and stops the applier. The mutations
+// enqueued concurrently are either applied or rejected with ErrClosed. Flush
+// must not be called after Close.
+func (w *WriteBehind) Close() error {
+	if !atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+		return nil
+	}
+	for atomic.LoadInt32(&w.pushing) > 0 {
+		runtime.Gosched()
+	}
+	w.Flush()
+	close(w.done)
+	return nil
+}
+
+// push appends the op to the queue, and can be called by many producers. A
+// mutation is rejected once the WriteBehind is closed, and Close waits for the
+// pushes that passed the check, so they are queued before its flush marker.
+func (w *WriteBehind) push(op *writeOp) error {
+	if op.flushed == nil {
+		atomic.AddInt32(&w.pushing, 1)
+		defer atomic.AddInt32(&w.pushing, -1)
+		if atomic.LoadInt32(&w.closed) == 1 {
+			return ErrClosed
+		}
+	}
+	prev := (*writeOp)(atomic.SwapPointer(&w.head, unsafe.Pointer(op)))
+	atomic.StorePointer(&prev.next, unsafe.Pointer(op))
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// pop removes the first op of the queue, and is called by the applier only.
+// It returns nil if the queue is empty or a push is in progress, in which case
+// the push wakes the applier again.
+func (w *WriteBehind) pop() *writeOp {
+	next := (*writeOp)(atomic.LoadPointer(&w.tail.next))
+	if next == nil {
+		return nil
+	}
+	w.tail = next
+	return next
+}
+
+func (w *WriteBehind) run() {
+	var batch []*writeOp
+	for {
+		select {
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+		for op := w.pop(); op != nil; op = w.pop() {
+			batch = append(batch, op)
+		}
+		w.apply(batch)
+		for i := range batch {
+			batch[i] = nil
+		}
+		batch = batch[:0]
+	}
+}
+
+// apply applies the batch under one write lock and releases the flush waiters.
+func (w *WriteBehind) apply(batch []*writeOp) {
+	var flushed []chan struct{}
+	w.tree.mu.Lock()
+	for _, op := range batch {
+		switch {
+		case op.flushed != nil:
+			flushed = append(flushed, op.flushed)
+		case op.delete:
+			w.tree.tree.Delete(op.item)
+		default:
+			w.tree.tree.Insert(op.item)
+		}
+	}
+	w.tree.mu.Unlock()
+	for _, c := range flushed {
+		close(c)
+	}
+}
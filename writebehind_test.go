@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Meng Huang (mhboy@outlook.com)
+// This package is licensed under a MIT license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWriteBehind(t *testing.T) {
+	w := NewWriteBehind(NewSync(2))
+	var wg sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				w.Insert(Int(p*500 + i))
+				if i%2 == 1 {
+					w.Delete(Int(p*500 + i))
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+	w.Flush()
+	if w.Tree().Length() != 2000 {
+		t.Error(w.Tree().Length())
+	}
+	w.Tree().Ascend(func(item Item) bool {
+		if item.(Int)%2 != 0 {
+			t.Error(item)
+		}
+		return true
+	})
+	w.Insert(Int(1))
+	if w.Close() != nil || w.Close() != nil || w.Tree().Search(Int(1)) == nil {
+		t.Error("")
+	}
+	testTraversal(w.Tree().Clone(), t)
+	if w.Insert(Int(2)) != ErrClosed || w.Delete(Int(1)) != ErrClosed || w.Tree().Length() != 2001 {
+		t.Error(w.Tree().Length())
+	}
+	other := NewWriteBehind(NewSync(2))
+	defer other.Close()
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("")
+		}
+	}()
+	other.Insert(nil)
+}
+
+func TestWriteBehindClose(t *testing.T) {
+	for n := 0; n < 20; n++ {
+		w := NewWriteBehind(NewSync(2))
+		var accepted int64
+		var wg sync.WaitGroup
+		for p := 0; p < 4; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; ; i++ {
+					if w.Insert(Int(p*100000+i)) != nil {
+						return
+					}
+					atomic.AddInt64(&accepted, 1)
+				}
+			}(p)
+		}
+		w.Insert(Int(-1))
+		w.Close()
+		wg.Wait()
+		if int64(w.Tree().Length()) != atomic.LoadInt64(&accepted)+1 {
+			t.Error(w.Tree().Length(), accepted)
+		}
+	}
+}